@@ -0,0 +1,190 @@
+package candles
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// CandleFunc is called with a candle of the instrument with the given
+// instrument token.
+type CandleFunc func(token uint32, c kite.HistoricalData)
+
+type tokenBar struct {
+	bar
+	volume    uint32
+	hasVolume bool
+	// pending is the volume of late ticks which arrived while no candle
+	// was open, which goes to the next candle.
+	pending int
+	// last is the time of the latest tick, so an earlier one arriving out
+	// of order doesn't set the close.
+	last time.Time
+}
+
+// Aggregator builds candles of a fixed duration from ticks. Candles are
+// aligned as by PeriodStart, their volume is the change of the ticks'
+// VolumeTraded and their OI that of the latest tick which has one.
+//
+// A candle is closed when a tick of a later period arrives, or by Flush
+// once its period has ended, as illiquid instruments may not tick for a
+// while.
+//
+//	a := candles.NewAggregator(5 * time.Minute)
+//	a.OnClose(func(token uint32, c kite.HistoricalData) { ... })
+//	ticker.OnTick(a.HandleTick)
+type Aggregator struct {
+	duration time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	bars     map[uint32]*tokenBar
+	onClose  CandleFunc
+	onUpdate CandleFunc
+}
+
+// NewAggregator returns an aggregator of candles of duration d.
+func NewAggregator(d time.Duration) *Aggregator {
+	return &Aggregator{
+		duration: d,
+		now:      time.Now,
+		bars:     make(map[uint32]*tokenBar),
+	}
+}
+
+// OnClose sets the callback which receives every closed candle.
+func (a *Aggregator) OnClose(f CandleFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onClose = f
+}
+
+// OnUpdate sets the callback which receives the open candle of an
+// instrument after every tick which changes it.
+func (a *Aggregator) OnUpdate(f CandleFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onUpdate = f
+}
+
+// Current returns the open candle of the instrument with the given
+// instrument token.
+func (a *Aggregator) Current(token uint32) (kite.HistoricalData, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	b, ok := a.bars[token]
+	if !ok || !b.open {
+		return kite.HistoricalData{}, false
+	}
+
+	return b.candle, true
+}
+
+// HandleTick adds a tick to the candle of its instrument. The tick's time
+// is its exchange timestamp, or the current time if it has none. Ticks of
+// periods before the open candle, or of a closed one, only add to the
+// volume of the open or next candle. It can be set as
+// the ticker's OnTick callback.
+func (a *Aggregator) HandleTick(tick kite.Tick) {
+	t := tick.Timestamp.Time
+	if t.IsZero() {
+		t = a.now()
+	}
+
+	a.mu.Lock()
+	b, ok := a.bars[tick.InstrumentToken]
+	if !ok {
+		b = &tokenBar{}
+		a.bars[tick.InstrumentToken] = b
+	}
+
+	// VolumeTraded is the volume of the day, so it goes back down when a
+	// new day starts. The volume of the first tick isn't known.
+	var volume int
+	if b.hasVolume {
+		if tick.VolumeTraded >= b.volume {
+			volume = int(tick.VolumeTraded - b.volume)
+		} else {
+			volume = int(tick.VolumeTraded)
+		}
+	}
+	b.volume, b.hasVolume = tick.VolumeTraded, true
+
+	var closed *kite.HistoricalData
+	start := PeriodStart(t, a.duration)
+	switch {
+	case b.start.IsZero() || start.After(b.start):
+		if b.open {
+			c := b.candle
+			closed = &c
+		}
+
+		b.reset(start, tick.LastPrice)
+		b.candle.Volume = b.pending + volume
+		b.pending = 0
+	case !b.open:
+		b.pending += volume
+	case start.Before(b.start):
+		b.candle.Volume += volume
+	default:
+		closing := tick.LastPrice
+		if t.Before(b.last) {
+			closing = b.candle.Close
+		}
+		b.merge(kite.HistoricalData{High: tick.LastPrice, Low: tick.LastPrice, Close: closing, Volume: volume})
+	}
+
+	if t.After(b.last) {
+		b.last = t
+	}
+
+	if tick.OI != 0 {
+		b.candle.OI = int(tick.OI)
+	}
+
+	update := b.candle
+	onClose, onUpdate := a.onClose, a.onUpdate
+	a.mu.Unlock()
+
+	if closed != nil && onClose != nil {
+		onClose(tick.InstrumentToken, *closed)
+	}
+
+	if onUpdate != nil {
+		onUpdate(tick.InstrumentToken, update)
+	}
+}
+
+// Flush closes the open candles whose period ended by now, which is
+// usually the current time. Call it periodically, as from a time.Ticker,
+// to close candles of instruments which stopped ticking.
+func (a *Aggregator) Flush(now time.Time) {
+	type closedCandle struct {
+		token  uint32
+		candle kite.HistoricalData
+	}
+
+	a.mu.Lock()
+	var closed []closedCandle
+	for token, b := range a.bars {
+		if b.open && !now.Before(b.start.Add(a.duration)) {
+			closed = append(closed, closedCandle{token, b.candle})
+			b.open = false
+		}
+	}
+	onClose := a.onClose
+	a.mu.Unlock()
+
+	if onClose == nil {
+		return
+	}
+
+	sort.Slice(closed, func(i, j int) bool { return closed[i].token < closed[j].token })
+
+	for _, c := range closed {
+		onClose(c.token, c.candle)
+	}
+}
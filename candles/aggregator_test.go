@@ -0,0 +1,124 @@
+package candles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodStart(t *testing.T) {
+	t.Parallel()
+	at := func(h, m, s int) time.Time { return time.Date(2023, 7, 3, h, m, s, 0, ist) }
+
+	tests := []struct {
+		t    time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{at(9, 15, 0), time.Minute, at(9, 15, 0)},
+		{at(9, 15, 59), time.Minute, at(9, 15, 0)},
+		{at(9, 17, 30), 3 * time.Minute, at(9, 15, 0)},
+		{at(9, 18, 0), 3 * time.Minute, at(9, 18, 0)},
+		{at(10, 44, 59), 45 * time.Minute, at(10, 0, 0)},
+		{at(15, 29, 0), 45 * time.Minute, at(15, 15, 0)},
+		{at(9, 22, 0), 7 * time.Minute, at(9, 22, 0)},
+		{at(9, 10, 0), 5 * time.Minute, at(9, 10, 0)},
+		{at(9, 14, 0), 5 * time.Minute, at(9, 10, 0)},
+		{at(9, 8, 0), 15 * time.Minute, at(9, 0, 0)},
+		{time.Date(2023, 7, 3, 3, 50, 0, 0, time.UTC), 45 * time.Minute, at(9, 15, 0)},
+	}
+
+	for _, tt := range tests {
+		got := PeriodStart(tt.t, tt.d)
+		require.True(t, tt.want.Equal(got), "%s of %s: got %s, want %s", tt.d, tt.t, got, tt.want)
+	}
+}
+
+type recorded struct {
+	token  uint32
+	candle kite.HistoricalData
+}
+
+func TestAggregator(t *testing.T) {
+	t.Parallel()
+	a := NewAggregator(5 * time.Minute)
+	var closed, updates []recorded
+	a.OnClose(func(token uint32, c kite.HistoricalData) { closed = append(closed, recorded{token, c}) })
+	a.OnUpdate(func(token uint32, c kite.HistoricalData) { updates = append(updates, recorded{token, c}) })
+
+	tick := func(token uint32, h, m, s int, price float64, volume, oi uint32) {
+		a.HandleTick(kite.Tick{
+			InstrumentToken: token,
+			Timestamp:       kite.Time{Time: time.Date(2023, 7, 3, h, m, s, 0, ist)},
+			LastPrice:       price,
+			VolumeTraded:    volume,
+			OI:              oi,
+		})
+	}
+
+	tick(1, 9, 15, 1, 100, 1000, 50)
+	tick(1, 9, 16, 0, 104, 1200, 0)
+	tick(1, 9, 19, 59, 98, 1500, 55)
+	tick(1, 9, 17, 0, 101, 1600, 0)
+	require.Equal(t, 0, len(closed))
+	require.Equal(t, 4, len(updates))
+
+	// An earlier tick arriving late doesn't set the close.
+	cur, ok := a.Current(1)
+	require.True(t, ok)
+	require.Equal(t, 98.0, cur.Close)
+	require.Equal(t, 600, cur.Volume)
+
+	// A tick of the next period closes the candle.
+	tick(1, 9, 20, 0, 99, 1700, 0)
+	require.Equal(t, 1, len(closed))
+	c := closed[0].candle
+	require.Equal(t, uint32(1), closed[0].token)
+	require.True(t, time.Date(2023, 7, 3, 9, 15, 0, 0, ist).Equal(c.Date.Time))
+	require.Equal(t, []float64{100, 104, 98, 98}, []float64{c.Open, c.High, c.Low, c.Close})
+	require.Equal(t, 600, c.Volume)
+	require.Equal(t, 55, c.OI)
+
+	cur, _ = a.Current(1)
+	require.Equal(t, 100, cur.Volume)
+	require.Equal(t, 55, cur.OI)
+
+	// Instruments have separate candles.
+	tick(2, 9, 21, 0, 10, 10, 0)
+	cur, ok = a.Current(2)
+	require.True(t, ok)
+	require.Equal(t, 0, cur.Volume)
+	require.Equal(t, 1, len(closed))
+
+	// Flush closes candles whose period ended.
+	a.Flush(time.Date(2023, 7, 3, 9, 24, 59, 0, ist))
+	require.Equal(t, 1, len(closed))
+	a.Flush(time.Date(2023, 7, 3, 9, 25, 0, 0, ist))
+	require.Equal(t, 3, len(closed))
+	require.Equal(t, uint32(1), closed[1].token)
+	require.Equal(t, uint32(2), closed[2].token)
+	_, ok = a.Current(1)
+	require.False(t, ok)
+
+	// A late tick of a closed candle adds its volume to the next one.
+	tick(1, 9, 24, 0, 90, 1750, 0)
+	a.Flush(time.Date(2023, 7, 3, 9, 30, 0, 0, ist))
+	require.Equal(t, 3, len(closed))
+	tick(1, 9, 31, 0, 97, 1800, 0)
+	cur, _ = a.Current(1)
+	require.Equal(t, 100, cur.Volume)
+	require.Equal(t, 97.0, cur.Open)
+
+	// The volume of the day starts over.
+	a.HandleTick(kite.Tick{
+		InstrumentToken: 1,
+		Timestamp:       kite.Time{Time: time.Date(2023, 7, 4, 9, 15, 0, 0, ist)},
+		LastPrice:       96,
+		VolumeTraded:    300,
+	})
+	cur, _ = a.Current(1)
+	require.Equal(t, 300, cur.Volume)
+	require.Equal(t, 55, cur.OI)
+}
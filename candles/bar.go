@@ -0,0 +1,65 @@
+package candles
+
+import (
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// Trading session open, in IST, which candles are aligned to.
+const (
+	sessionOpenHour   = 9
+	sessionOpenMinute = 15
+)
+
+// PeriodStart returns the start of the candle of duration d which t falls
+// in. Candles are aligned to the 09:15 IST open of t's day, as Kite's are, so
+// 45 minute candles start at 09:15, 10:00, 10:45 and so on. Times before the
+// open fall in candles counted back from it.
+func PeriodStart(t time.Time, d time.Duration) time.Time {
+	t = t.In(ist)
+	open := time.Date(t.Year(), t.Month(), t.Day(), sessionOpenHour, sessionOpenMinute, 0, 0, ist)
+
+	n := t.Sub(open) / d
+	if t.Before(open.Add(n * d)) {
+		n--
+	}
+
+	return open.Add(n * d)
+}
+
+// bar builds a candle from prices within its period.
+type bar struct {
+	candle kite.HistoricalData
+	start  time.Time
+	open   bool
+}
+
+// reset starts the candle of the period starting at start.
+func (b *bar) reset(start time.Time, price float64) {
+	b.candle = kite.HistoricalData{
+		Date:  kite.Time{Time: start},
+		Open:  price,
+		High:  price,
+		Low:   price,
+		Close: price,
+		OI:    b.candle.OI,
+	}
+	b.start = start
+	b.open = true
+}
+
+// merge adds a candle, or a trade as a candle with a single price, to the
+// bar.
+func (b *bar) merge(c kite.HistoricalData) {
+	if c.High > b.candle.High {
+		b.candle.High = c.High
+	}
+
+	if c.Low < b.candle.Low {
+		b.candle.Low = c.Low
+	}
+
+	b.candle.Close = c.Close
+	b.candle.Volume += c.Volume
+}
@@ -0,0 +1,123 @@
+package candles
+
+import (
+	"sync"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// Resampler combines candles, such as stored 1 minute ones, into candles of
+// a longer duration, aligned as by PeriodStart. Their volume is the sum of
+// the candles' volumes and their OI that of the last candle.
+//
+//	r := candles.NewResampler(time.Minute, 45*time.Minute)
+//	r.OnClose(func(c kite.HistoricalData) { ... })
+//	for _, c := range minuteCandles {
+//		r.Add(c)
+//	}
+//	r.Flush()
+type Resampler struct {
+	source   time.Duration
+	duration time.Duration
+
+	mu       sync.Mutex
+	bar      bar
+	onClose  func(kite.HistoricalData)
+	onUpdate func(kite.HistoricalData)
+}
+
+// NewResampler returns a resampler of candles of duration source into
+// candles of duration d. A candle is closed as soon as a candle reaching
+// the end of its period is added; with a source of 0 it is closed only by a
+// candle of a later period or by Flush.
+func NewResampler(source, d time.Duration) *Resampler {
+	return &Resampler{source: source, duration: d}
+}
+
+// OnClose sets the callback which receives every closed candle.
+func (r *Resampler) OnClose(f func(kite.HistoricalData)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onClose = f
+}
+
+// OnUpdate sets the callback which receives the open candle after every
+// candle added to it.
+func (r *Resampler) OnUpdate(f func(kite.HistoricalData)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onUpdate = f
+}
+
+// Add adds a candle, which is not before the ones added so far. Candles
+// of periods before the open one are ignored.
+func (r *Resampler) Add(c kite.HistoricalData) {
+	r.mu.Lock()
+	var prev *kite.HistoricalData
+	start := PeriodStart(c.Date.Time, r.duration)
+	switch {
+	case r.bar.start.IsZero() || start.After(r.bar.start):
+		if r.bar.open {
+			p := r.bar.candle
+			prev = &p
+		}
+
+		r.bar.reset(start, c.Open)
+		r.bar.merge(c)
+	case !r.bar.open || start.Before(r.bar.start):
+		r.mu.Unlock()
+		return
+	default:
+		r.bar.merge(c)
+	}
+	r.bar.candle.OI = c.OI
+
+	update := r.bar.candle
+	if r.source > 0 && !c.Date.Add(r.source).Before(start.Add(r.duration)) {
+		r.bar.open = false
+	}
+	done := !r.bar.open
+	onClose, onUpdate := r.onClose, r.onUpdate
+	r.mu.Unlock()
+
+	if prev != nil && onClose != nil {
+		onClose(*prev)
+	}
+
+	if onUpdate != nil {
+		onUpdate(update)
+	}
+
+	if done && onClose != nil {
+		onClose(update)
+	}
+}
+
+// Flush closes the open candle, if any.
+func (r *Resampler) Flush() {
+	r.mu.Lock()
+	closed, open := r.bar.candle, r.bar.open
+	r.bar.open = false
+	onClose := r.onClose
+	r.mu.Unlock()
+
+	if open && onClose != nil {
+		onClose(closed)
+	}
+}
+
+// Resample combines candles in time order into candles of duration d,
+// aligned as by PeriodStart. The last candle returned may span less than
+// d, as Kite's last candle does before its period ends.
+func Resample(candles []kite.HistoricalData, d time.Duration) []kite.HistoricalData {
+	var out []kite.HistoricalData
+	r := NewResampler(0, d)
+	r.OnClose(func(c kite.HistoricalData) { out = append(out, c) })
+	for _, c := range candles {
+		r.Add(c)
+	}
+	r.Flush()
+
+	return out
+}
@@ -0,0 +1,78 @@
+package candles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResample(t *testing.T) {
+	t.Parallel()
+	day := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 375)
+	for i := range day {
+		day[i].Open = float64(i)
+		day[i].High = float64(i) + 2
+		day[i].Low = float64(i) - 1
+		day[i].Volume = 1
+	}
+
+	got := Resample(day, 45*time.Minute)
+	require.Equal(t, 9, len(got))
+	c := got[1]
+	require.True(t, time.Date(2023, 7, 3, 10, 0, 0, 0, ist).Equal(c.Date.Time))
+	require.Equal(t, []float64{45, 91, 44, 89}, []float64{c.Open, c.High, c.Low, c.Close})
+	require.Equal(t, 45, c.Volume)
+	require.Equal(t, 200+89, c.OI)
+
+	// The last candle of the session spans 15:15 to 15:30.
+	require.True(t, time.Date(2023, 7, 3, 15, 15, 0, 0, ist).Equal(got[8].Date.Time))
+	require.Equal(t, 15, got[8].Volume)
+
+	require.Equal(t, 125, len(Resample(day, 3*time.Minute)))
+	require.Equal(t, 54, len(Resample(day, 7*time.Minute)))
+	require.Equal(t, 0, len(Resample(nil, time.Hour)))
+
+	// Days are aligned to their own open.
+	next := minuteCandles(time.Date(2023, 7, 4, 9, 15, 0, 0, ist), 1)
+	got = Resample(append(day[370:], next...), time.Hour)
+	require.Equal(t, 2, len(got))
+	require.True(t, time.Date(2023, 7, 3, 15, 15, 0, 0, ist).Equal(got[0].Date.Time))
+	require.True(t, next[0].Date.Equal(got[1].Date.Time))
+}
+
+func TestResampler(t *testing.T) {
+	t.Parallel()
+	r := NewResampler(time.Minute, 3*time.Minute)
+	var events []string
+	r.OnClose(func(c kite.HistoricalData) { events = append(events, "close "+c.Date.Format("15:04")) })
+	r.OnUpdate(func(c kite.HistoricalData) { events = append(events, "update "+c.Date.Format("15:04")) })
+
+	candles := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 5)
+	for _, c := range candles[:3] {
+		r.Add(c)
+	}
+
+	// The candle closes with its last minute.
+	require.Equal(t, []string{"update 09:15", "update 09:15", "update 09:15", "close 09:15"}, events)
+
+	// Candles of closed periods are ignored.
+	r.Add(candles[1])
+	require.Equal(t, 4, len(events))
+
+	r.Add(candles[4])
+	r.Flush()
+	r.Flush()
+	require.Equal(t, []string{"update 09:18", "close 09:18"}, events[4:])
+
+	// Without the source duration, a later candle closes the period.
+	r = NewResampler(0, 3*time.Minute)
+	events = nil
+	r.OnClose(func(c kite.HistoricalData) { events = append(events, "close "+c.Date.Format("15:04")) })
+	r.OnUpdate(func(c kite.HistoricalData) { events = append(events, "update "+c.Date.Format("15:04")) })
+	for _, c := range candles[2:4] {
+		r.Add(c)
+	}
+	require.Equal(t, []string{"update 09:15", "close 09:15", "update 09:18"}, events)
+}
@@ -0,0 +1,354 @@
+package candles
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// A candle is stored as a fixed size record of its time in unix seconds,
+// open, high, low and close, volume and OI, all little endian.
+const recordSize = 7 * 8
+
+// An index entry is the day as YYYYMMDD in IST, followed by the position of
+// the day's first record and the number of its records.
+const indexEntrySize = 3 * 4
+
+// dayIndex locates the records of a day in the data file.
+type dayIndex struct {
+	day   uint32
+	first uint32
+	count uint32
+}
+
+// series is the data and index files of an instrument and interval. The
+// data file holds the candles in order, so new candles are appended, and
+// the index lets queries read only the days they cover.
+type series struct {
+	dataPath  string
+	indexPath string
+	days      []dayIndex
+	last      time.Time
+}
+
+func encodeCandle(c kite.HistoricalData) []byte {
+	b := make([]byte, recordSize)
+	binary.LittleEndian.PutUint64(b[0:], uint64(c.Date.Unix()))
+	binary.LittleEndian.PutUint64(b[8:], math.Float64bits(c.Open))
+	binary.LittleEndian.PutUint64(b[16:], math.Float64bits(c.High))
+	binary.LittleEndian.PutUint64(b[24:], math.Float64bits(c.Low))
+	binary.LittleEndian.PutUint64(b[32:], math.Float64bits(c.Close))
+	binary.LittleEndian.PutUint64(b[40:], uint64(c.Volume))
+	binary.LittleEndian.PutUint64(b[48:], uint64(c.OI))
+	return b
+}
+
+func decodeCandle(b []byte) kite.HistoricalData {
+	return kite.HistoricalData{
+		Date:   kite.Time{Time: time.Unix(int64(binary.LittleEndian.Uint64(b[0:])), 0).In(ist)},
+		Open:   math.Float64frombits(binary.LittleEndian.Uint64(b[8:])),
+		High:   math.Float64frombits(binary.LittleEndian.Uint64(b[16:])),
+		Low:    math.Float64frombits(binary.LittleEndian.Uint64(b[24:])),
+		Close:  math.Float64frombits(binary.LittleEndian.Uint64(b[32:])),
+		Volume: int(binary.LittleEndian.Uint64(b[40:])),
+		OI:     int(binary.LittleEndian.Uint64(b[48:])),
+	}
+}
+
+// dayKey returns the IST day of t as YYYYMMDD.
+func dayKey(t time.Time) uint32 {
+	y, m, d := t.In(ist).Date()
+	return uint32(y*10000 + int(m)*100 + d)
+}
+
+// openSeries loads the index of a series, rebuilding it from the data file
+// if it is missing or doesn't match. A partly written record at the end of
+// the data file, left by an interrupted append, is dropped.
+func openSeries(dataPath, indexPath string) (*series, error) {
+	s := &series{dataPath: dataPath, indexPath: indexPath}
+
+	fi, err := os.Stat(dataPath)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	n := fi.Size() / recordSize
+	if fi.Size()%recordSize != 0 {
+		if err := os.Truncate(dataPath, n*recordSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if n == 0 {
+		return s, nil
+	}
+
+	if s.loadIndex() != nil || s.count() != int(n) {
+		candles, err := s.readRange(0, int(n))
+		if err != nil {
+			return nil, err
+		}
+
+		s.buildIndex(candles)
+		if err := s.saveIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	last, err := s.readRange(int(n)-1, int(n))
+	if err != nil {
+		return nil, err
+	}
+	s.last = last[0].Date.Time
+
+	return s, nil
+}
+
+func (s *series) loadIndex() error {
+	b, err := ioutil.ReadFile(s.indexPath)
+	if err != nil {
+		return err
+	}
+
+	s.days = make([]dayIndex, 0, len(b)/indexEntrySize)
+	for ; len(b) >= indexEntrySize; b = b[indexEntrySize:] {
+		s.days = append(s.days, dayIndex{
+			day:   binary.LittleEndian.Uint32(b[0:]),
+			first: binary.LittleEndian.Uint32(b[4:]),
+			count: binary.LittleEndian.Uint32(b[8:]),
+		})
+	}
+
+	return nil
+}
+
+// saveIndex atomically replaces the index file.
+func (s *series) saveIndex() error {
+	b := make([]byte, 0, len(s.days)*indexEntrySize)
+	for _, d := range s.days {
+		e := make([]byte, indexEntrySize)
+		binary.LittleEndian.PutUint32(e[0:], d.day)
+		binary.LittleEndian.PutUint32(e[4:], d.first)
+		binary.LittleEndian.PutUint32(e[8:], d.count)
+		b = append(b, e...)
+	}
+
+	return writeFileAtomic(s.indexPath, b)
+}
+
+// count returns the number of stored candles.
+func (s *series) count() int {
+	if len(s.days) == 0 {
+		return 0
+	}
+
+	d := s.days[len(s.days)-1]
+	return int(d.first + d.count)
+}
+
+// buildIndex indexes the stored candles, which are in order.
+func (s *series) buildIndex(candles []kite.HistoricalData) {
+	s.days = s.days[:0]
+	for i, c := range candles {
+		s.days = indexCandle(s.days, i, c)
+	}
+}
+
+// indexCandle adds the i-th candle to the index entries days.
+func indexCandle(days []dayIndex, i int, c kite.HistoricalData) []dayIndex {
+	day := dayKey(c.Date.Time)
+	if n := len(days); n > 0 && days[n-1].day == day {
+		days[n-1].count++
+		return days
+	}
+
+	return append(days, dayIndex{day: day, first: uint32(i), count: 1})
+}
+
+// readRange reads the candles at positions [from, to).
+func (s *series) readRange(from, to int) ([]kite.HistoricalData, error) {
+	if from >= to {
+		return nil, nil
+	}
+
+	f, err := os.Open(s.dataPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := make([]byte, (to-from)*recordSize)
+	if _, err := f.ReadAt(b, int64(from)*recordSize); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	out := make([]kite.HistoricalData, 0, to-from)
+	for ; len(b) >= recordSize; b = b[recordSize:] {
+		out = append(out, decodeCandle(b))
+	}
+
+	return out, nil
+}
+
+// query reads the candles between from and to, both inclusive.
+func (s *series) query(from, to time.Time) ([]kite.HistoricalData, error) {
+	first := sort.Search(len(s.days), func(i int) bool { return s.days[i].day >= dayKey(from) })
+	last := sort.Search(len(s.days), func(i int) bool { return s.days[i].day > dayKey(to) })
+	if first >= last {
+		return nil, nil
+	}
+
+	candles, err := s.readRange(int(s.days[first].first), int(s.days[last-1].first+s.days[last-1].count))
+	if err != nil {
+		return nil, err
+	}
+
+	out := candles[:0]
+	for _, c := range candles {
+		if !c.Date.Before(from) && !c.Date.After(to) {
+			out = append(out, c)
+		}
+	}
+
+	return out, nil
+}
+
+// write stores candles, which are in order and without duplicates. Candles
+// after the last stored one are appended; a candle at the time of the last
+// one replaces it, as it may have been stored before its period ended. Any
+// other candles are merged into the series by rewriting it.
+func (s *series) write(candles []kite.HistoricalData) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.dataPath), 0755); err != nil {
+		return err
+	}
+
+	n := s.count()
+	switch {
+	case n == 0 || candles[0].Date.After(s.last):
+		return s.append(n, candles)
+	case candles[0].Date.Equal(s.last):
+		return s.append(n-1, candles)
+	}
+
+	stored, err := s.readRange(0, n)
+	if err != nil {
+		return err
+	}
+
+	merged := mergeCandles(stored, candles)
+	b := make([]byte, 0, len(merged)*recordSize)
+	for _, c := range merged {
+		b = append(b, encodeCandle(c)...)
+	}
+
+	if err := writeFileAtomic(s.dataPath, b); err != nil {
+		return err
+	}
+
+	s.buildIndex(merged)
+	s.last = merged[len(merged)-1].Date.Time
+	return s.saveIndex()
+}
+
+// append writes candles from position at, which is at most the number of
+// stored candles. The index is only updated once the candles are written.
+func (s *series) append(at int, candles []kite.HistoricalData) error {
+	days := truncateIndex(s.days, at)
+	for i, c := range candles {
+		days = indexCandle(days, at+i, c)
+	}
+
+	f, err := os.OpenFile(s.dataPath, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 0, len(candles)*recordSize)
+	for _, c := range candles {
+		b = append(b, encodeCandle(c)...)
+	}
+
+	if _, err := f.WriteAt(b, int64(at)*recordSize); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	s.days = days
+	s.last = candles[len(candles)-1].Date.Time
+
+	return s.saveIndex()
+}
+
+// truncateIndex returns a copy of the index entries of the candles before
+// position n.
+func truncateIndex(days []dayIndex, n int) []dayIndex {
+	out := make([]dayIndex, 0, len(days)+1)
+	for _, d := range days {
+		if int(d.first) >= n {
+			break
+		}
+
+		if int(d.first+d.count) > n {
+			d.count = uint32(n) - d.first
+		}
+		out = append(out, d)
+	}
+
+	return out
+}
+
+// mergeCandles merges two ordered lists of candles. Candles of b replace
+// those of a at the same time.
+func mergeCandles(a, b []kite.HistoricalData) []kite.HistoricalData {
+	out := make([]kite.HistoricalData, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0].Date.Before(b[0].Date.Time):
+			out, a = append(out, a[0]), a[1:]
+		case a[0].Date.Equal(b[0].Date.Time):
+			out, a, b = append(out, b[0]), a[1:], b[1:]
+		default:
+			out, b = append(out, b[0]), b[1:]
+		}
+	}
+
+	out = append(out, a...)
+	return append(out, b...)
+}
+
+// writeFileAtomic writes b to a temporary file and renames it to path.
+func writeFileAtomic(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,189 @@
+package candles
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+// minuteCandles returns n minute candles from start, with their index as
+// the close.
+func minuteCandles(start time.Time, n int) []kite.HistoricalData {
+	candles := make([]kite.HistoricalData, n)
+	for i := range candles {
+		candles[i] = kite.HistoricalData{
+			Date:   kite.Time{Time: start.Add(time.Duration(i) * time.Minute)},
+			Open:   1.5,
+			High:   2.25,
+			Low:    0.75,
+			Close:  float64(i),
+			Volume: 100 + i,
+			OI:     200 + i,
+		}
+	}
+
+	return candles
+}
+
+func tempSeries(t *testing.T) (*series, func()) {
+	dir, err := ioutil.TempDir("", "candles")
+	require.Nil(t, err)
+
+	s, err := openSeries(filepath.Join(dir, "s.dat"), filepath.Join(dir, "s.idx"))
+	require.Nil(t, err)
+
+	return s, func() { os.RemoveAll(dir) }
+}
+
+func requireCandles(t *testing.T, want, got []kite.HistoricalData) {
+	require.Equal(t, len(want), len(got))
+	for i := range want {
+		require.True(t, want[i].Date.Equal(got[i].Date.Time), "candle %d at %s, want %s", i, got[i].Date, want[i].Date)
+		want[i].Date = got[i].Date
+		require.Equal(t, want[i], got[i])
+	}
+}
+
+func TestEncodeCandle(t *testing.T) {
+	t.Parallel()
+	c := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 1)[0]
+	got := decodeCandle(encodeCandle(c))
+	require.Equal(t, ist, got.Date.Location())
+	requireCandles(t, []kite.HistoricalData{c}, []kite.HistoricalData{got})
+}
+
+func TestSeriesWrite(t *testing.T) {
+	t.Parallel()
+	s, cleanup := tempSeries(t)
+	defer cleanup()
+
+	day1 := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 375)
+	day2 := minuteCandles(time.Date(2023, 7, 4, 9, 15, 0, 0, ist), 375)
+
+	require.Nil(t, s.write(day1[:100]))
+	require.Nil(t, s.write(day1[100:]))
+	require.Nil(t, s.write(day2[:10]))
+	require.Equal(t, 385, s.count())
+	require.Equal(t, []dayIndex{{20230703, 0, 375}, {20230704, 375, 10}}, s.days)
+
+	// The last candle is replaced.
+	last := day2[9]
+	last.Close = 99
+	require.Nil(t, s.write([]kite.HistoricalData{last, day2[10]}))
+	require.Equal(t, 386, s.count())
+	got, err := s.query(day2[0].Date.Time, day2[10].Date.Time)
+	require.Nil(t, err)
+	require.Equal(t, 11, len(got))
+	require.Equal(t, 99.0, got[9].Close)
+
+	// Earlier candles are merged.
+	first := day1[0]
+	first.Close = -1
+	early := minuteCandles(time.Date(2023, 6, 30, 15, 29, 0, 0, ist), 1)
+	require.Nil(t, s.write(append(early, first)))
+	require.Equal(t, 387, s.count())
+	require.Equal(t, []dayIndex{{20230630, 0, 1}, {20230703, 1, 375}, {20230704, 376, 11}}, s.days)
+	got, err = s.query(early[0].Date.Time, day1[1].Date.Time)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(got))
+	require.Equal(t, -1.0, got[1].Close)
+
+	// The series is reopened from its files.
+	r, err := openSeries(s.dataPath, s.indexPath)
+	require.Nil(t, err)
+	require.Equal(t, s.days, r.days)
+	require.True(t, r.last.Equal(day2[10].Date.Time))
+}
+
+func TestSeriesWriteFails(t *testing.T) {
+	t.Parallel()
+	s, cleanup := tempSeries(t)
+	defer cleanup()
+
+	day := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 10)
+	require.Nil(t, s.write(day[:5]))
+	days, last := append([]dayIndex(nil), s.days...), s.last
+
+	// The data file can't be written, so the index is left as it was.
+	require.Nil(t, os.Rename(s.dataPath, s.dataPath+".bak"))
+	require.Nil(t, os.Mkdir(s.dataPath, 0755))
+	require.Error(t, s.write(day[4:]))
+	require.Equal(t, days, s.days)
+	require.True(t, s.last.Equal(last))
+
+	require.Nil(t, os.Remove(s.dataPath))
+	require.Nil(t, os.Rename(s.dataPath+".bak", s.dataPath))
+	require.Nil(t, s.write(day[4:]))
+	require.Equal(t, []dayIndex{{20230703, 0, 10}}, s.days)
+
+	got, err := s.query(day[0].Date.Time, day[9].Date.Time)
+	require.Nil(t, err)
+	requireCandles(t, day, got)
+}
+
+func TestSeriesQuery(t *testing.T) {
+	t.Parallel()
+	s, cleanup := tempSeries(t)
+	defer cleanup()
+
+	var candles []kite.HistoricalData
+	for d := 3; d <= 7; d++ {
+		candles = append(candles, minuteCandles(time.Date(2023, 7, d, 9, 15, 0, 0, ist), 375)...)
+	}
+	require.Nil(t, s.write(candles))
+
+	got, err := s.query(candles[400].Date.Time, candles[1000].Date.Time)
+	require.Nil(t, err)
+	requireCandles(t, candles[400:1001], got)
+
+	// Bounds in other zones and between days.
+	got, err = s.query(time.Date(2023, 7, 3, 0, 0, 0, 0, time.UTC), time.Date(2023, 7, 3, 23, 0, 0, 0, time.UTC))
+	require.Nil(t, err)
+	requireCandles(t, candles[:375], got)
+
+	got, err = s.query(time.Date(2023, 7, 8, 0, 0, 0, 0, ist), time.Date(2023, 7, 9, 0, 0, 0, 0, ist))
+	require.Nil(t, err)
+	require.Equal(t, 0, len(got))
+
+	got, err = s.query(candles[10].Date.Time, candles[5].Date.Time)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(got))
+}
+
+func TestOpenSeriesRecovers(t *testing.T) {
+	t.Parallel()
+	s, cleanup := tempSeries(t)
+	defer cleanup()
+
+	candles := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 20)
+	require.Nil(t, s.write(candles))
+
+	// A partly written record is dropped and the stale index rebuilt.
+	f, err := os.OpenFile(s.dataPath, os.O_WRONLY|os.O_APPEND, 0644)
+	require.Nil(t, err)
+	_, err = f.Write(encodeCandle(candles[0])[:20])
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	require.Nil(t, ioutil.WriteFile(s.indexPath, nil, 0644))
+
+	r, err := openSeries(s.dataPath, s.indexPath)
+	require.Nil(t, err)
+	require.Equal(t, 20, r.count())
+	fi, err := os.Stat(s.dataPath)
+	require.Nil(t, err)
+	require.Equal(t, int64(20*recordSize), fi.Size())
+
+	// A missing index is rebuilt.
+	require.Nil(t, os.Remove(s.indexPath))
+	r, err = openSeries(s.dataPath, s.indexPath)
+	require.Nil(t, err)
+	require.Equal(t, []dayIndex{{20230703, 0, 20}}, r.days)
+	got, err := r.query(candles[0].Date.Time, candles[19].Date.Time)
+	require.Nil(t, err)
+	requireCandles(t, candles, got)
+}
@@ -0,0 +1,197 @@
+// Package candles keeps historical candles of Kite Connect instruments on
+// disk, so they are downloaded once and can be queried offline, and builds
+// candles of any duration from ticks or from shorter candles.
+//
+// Each instrument and interval is stored as a data file of fixed size
+// records in time order, which new candles are appended to, and an index of
+// where each day's candles start.
+//
+//	s := candles.NewStore(kc, "/var/lib/candles")
+//	if _, err := s.Sync(ctx, 256265, kite.IntervalMinute); err != nil {
+//		return err
+//	}
+//	data, err := s.Query(256265, kite.IntervalMinute, from, to)
+package candles
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+var ist = loadIST()
+
+func loadIST() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		return loc
+	}
+
+	return time.FixedZone("IST", 5*60*60+30*60)
+}
+
+type seriesKey struct {
+	token    int
+	interval string
+}
+
+// Store is an on-disk store of candles. It is safe for concurrent use, but
+// a directory must not be shared by several stores.
+type Store struct {
+	client *kite.Client
+	dir    string
+	now    func() time.Time
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+}
+
+// NewStore returns a store which keeps its files in dir and syncs with c.
+// c may be nil for a store which is only queried.
+func NewStore(c *kite.Client, dir string) *Store {
+	return &Store{
+		client: c,
+		dir:    dir,
+		now:    time.Now,
+		series: make(map[seriesKey]*series),
+	}
+}
+
+// open returns the series of an instrument and interval. s.mu must be held.
+func (s *Store) open(token int, interval string) (*series, error) {
+	key := seriesKey{token, interval}
+	if sr, ok := s.series[key]; ok {
+		return sr, nil
+	}
+
+	base := filepath.Join(s.dir, strconv.Itoa(token), interval)
+	sr, err := openSeries(base+".dat", base+".idx")
+	if err != nil {
+		return nil, err
+	}
+
+	s.series[key] = sr
+	return sr, nil
+}
+
+// Write stores candles of an instrument and interval, such as those
+// returned by GetHistoricalData. Candles at the time of stored ones replace
+// them.
+func (s *Store) Write(token int, interval string, candles []kite.HistoricalData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sr, err := s.open(token, interval)
+	if err != nil {
+		return err
+	}
+
+	return sr.write(sortCandles(candles))
+}
+
+// sortCandles returns a copy of candles in time order, keeping the last of
+// candles with the same time.
+func sortCandles(candles []kite.HistoricalData) []kite.HistoricalData {
+	out := append([]kite.HistoricalData(nil), candles...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Date.Before(out[j].Date.Time)
+	})
+
+	n := 0
+	for i, c := range out {
+		if i > 0 && c.Date.Equal(out[n-1].Date.Time) {
+			out[n-1] = c
+			continue
+		}
+
+		out[n] = c
+		n++
+	}
+
+	return out[:n]
+}
+
+// Query returns the stored candles of an instrument and interval between
+// from and to, both inclusive, in time order.
+func (s *Store) Query(token int, interval string, from, to time.Time) ([]kite.HistoricalData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sr, err := s.open(token, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return sr.query(from, to)
+}
+
+// Last returns the last stored candle of an instrument and interval.
+func (s *Store) Last(token int, interval string) (kite.HistoricalData, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sr, err := s.open(token, interval)
+	if err != nil {
+		return kite.HistoricalData{}, false, err
+	}
+
+	n := sr.count()
+	if n == 0 {
+		return kite.HistoricalData{}, false, nil
+	}
+
+	last, err := sr.readRange(n-1, n)
+	if err != nil {
+		return kite.HistoricalData{}, false, err
+	}
+
+	return last[0], true, nil
+}
+
+// Sync fetches the candles of an instrument and interval since the last
+// stored one, which is fetched again as its period may not have ended when
+// it was stored. A series with no candles is filled with as much history
+// as a single request returns; use SyncFrom to backfill further. It returns
+// the number of candles fetched.
+func (s *Store) Sync(ctx context.Context, token int, interval string) (int, error) {
+	days := kite.MaxHistoricalDays(interval)
+	if days == 0 {
+		return 0, fmt.Errorf("candles: unknown interval %s", interval)
+	}
+
+	return s.SyncFrom(ctx, token, interval, s.now().AddDate(0, 0, -days))
+}
+
+// SyncFrom is Sync with the time to fetch series with no candles from.
+// Series which end before from are synced from their last candle.
+func (s *Store) SyncFrom(ctx context.Context, token int, interval string, from time.Time) (int, error) {
+	if s.client == nil {
+		return 0, fmt.Errorf("candles: store has no client to sync with")
+	}
+
+	last, ok, err := s.Last(token, interval)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		from = last.Date.Time
+	}
+
+	// The API reads the range in IST.
+	data, err := s.client.GetHistoricalDataRangeWithContext(ctx, token, interval, from.In(ist), s.now().In(ist), false, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.Write(token, interval, data); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
@@ -0,0 +1,103 @@
+package candles
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/santoshanand/at-kite/kitetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreWriteQuery(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "candles")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewStore(nil, dir)
+	candles := minuteCandles(time.Date(2023, 7, 3, 9, 15, 0, 0, ist), 10)
+
+	// Candles are sorted and the last of duplicates kept.
+	dup := candles[4]
+	dup.Close = 42
+	require.Nil(t, s.Write(256265, kite.IntervalMinute, []kite.HistoricalData{candles[5], candles[4], dup, candles[6]}))
+	require.Nil(t, s.Write(256265, kite.IntervalMinute, candles[:4]))
+
+	got, err := s.Query(256265, kite.IntervalMinute, candles[0].Date.Time, candles[9].Date.Time)
+	require.Nil(t, err)
+	require.Equal(t, 7, len(got))
+	require.Equal(t, 42.0, got[4].Close)
+
+	last, ok, err := s.Last(256265, kite.IntervalMinute)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.True(t, last.Date.Equal(candles[6].Date.Time))
+
+	// Instruments and intervals are kept apart.
+	_, ok, err = s.Last(256265, kite.IntervalDay)
+	require.Nil(t, err)
+	require.False(t, ok)
+	got, err = NewStore(nil, dir).Query(260105, kite.IntervalMinute, candles[0].Date.Time, candles[9].Date.Time)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(got))
+
+	// A new store reads the same files.
+	got, err = NewStore(nil, dir).Query(256265, kite.IntervalMinute, candles[0].Date.Time, candles[9].Date.Time)
+	require.Nil(t, err)
+	require.Equal(t, 7, len(got))
+
+	_, err = s.Sync(context.Background(), 256265, kite.IntervalMinute)
+	require.Error(t, err)
+}
+
+func TestStoreSync(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "candles")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	srv := kitetest.NewServer()
+	defer srv.Close()
+	kc := srv.NewClient()
+	kc.SetRetryPolicy(kite.NoRetryPolicy())
+
+	start := time.Date(2023, 7, 3, 9, 15, 0, 0, ist)
+	candles := minuteCandles(start, 375)
+	srv.SetCandles(256265, kite.IntervalMinute, candles[:100])
+
+	s := NewStore(kc, dir)
+	now := candles[99].Date.Time
+	s.now = func() time.Time { return now }
+
+	n, err := s.Sync(context.Background(), 256265, kite.IntervalMinute)
+	require.Nil(t, err)
+	require.Equal(t, 100, n)
+	reqs := srv.Requests()
+	require.Equal(t, 1, len(reqs))
+	require.Equal(t, "2023-05-04 10:54:00", reqs[0].Query.Get("from"))
+	require.Equal(t, "1", reqs[0].Query.Get("oi"))
+
+	// The last candle changed since it was stored.
+	candles[99].Close = 1000
+	srv.SetCandles(256265, kite.IntervalMinute, candles)
+	now = candles[374].Date.Time
+
+	n, err = s.Sync(context.Background(), 256265, kite.IntervalMinute)
+	require.Nil(t, err)
+	require.Equal(t, 276, n)
+	reqs = srv.Requests()
+	require.Equal(t, 2, len(reqs))
+	require.Equal(t, "2023-07-03 10:54:00", reqs[1].Query.Get("from"))
+	require.Equal(t, "2023-07-03 15:29:00", reqs[1].Query.Get("to"))
+
+	got, err := s.Query(256265, kite.IntervalMinute, start, now)
+	require.Nil(t, err)
+	requireCandles(t, candles, got)
+
+	_, err = s.Sync(context.Background(), 256265, "2minute")
+	require.Error(t, err)
+}
@@ -0,0 +1,295 @@
+// Package greeks prices options with the Black-Scholes model and computes
+// their implied volatility and greeks.
+//
+// Volatilities, rates and dividend yields are annualized decimals, so 15%
+// is 0.15. Time is in years; Model.TimeToExpiry measures it in trading
+// time for instruments listed on Indian exchanges.
+//
+//	m := greeks.Model{Rate: 0.07}
+//	r, err := m.Compute(inst, optionTick.LastPrice, niftyTick.LastPrice, time.Now())
+package greeks
+
+import (
+	"errors"
+	"math"
+)
+
+// OptionType is the type of an option, as in the InstrumentType of a
+// kite.Instrument.
+type OptionType string
+
+// Option types.
+const (
+	Call OptionType = "CE"
+	Put  OptionType = "PE"
+)
+
+var (
+	// ErrExpired is returned for options with no time left to expiry.
+	ErrExpired = errors.New("greeks: option has expired")
+	// ErrPriceOutOfBounds is returned when no volatility yields the option
+	// price, as with a price below the intrinsic value.
+	ErrPriceOutOfBounds = errors.New("greeks: price out of bounds")
+	// ErrNoConvergence is returned when the implied volatility solver
+	// fails to converge.
+	ErrNoConvergence = errors.New("greeks: implied volatility did not converge")
+	// ErrInvalidInput is returned for non-positive prices, strikes or
+	// volatilities and unknown option types.
+	ErrInvalidInput = errors.New("greeks: invalid input")
+)
+
+// Bounds and tolerance of the implied volatility solver.
+const (
+	minVol       = 1e-6
+	maxVol       = 10
+	volTolerance = 1e-10
+	maxNewton    = 50
+	maxBrent     = 200
+)
+
+// Greeks are the sensitivities of an option price. Theta is per trading
+// day, vega per 1% change in volatility and rho per 1% change in the rate.
+type Greeks struct {
+	Delta float64
+	Gamma float64
+	Theta float64
+	Vega  float64
+	Rho   float64
+}
+
+// params are the inputs of the model for an option.
+type params struct {
+	typ    OptionType
+	spot   float64
+	strike float64
+	t      float64
+	rate   float64
+	div    float64
+}
+
+func (p params) validate() error {
+	if p.typ != Call && p.typ != Put {
+		return ErrInvalidInput
+	}
+
+	if p.spot <= 0 || p.strike <= 0 || math.IsNaN(p.spot) || math.IsNaN(p.strike) || math.IsNaN(p.t) {
+		return ErrInvalidInput
+	}
+
+	if p.t <= 0 {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// validVol reports whether the model is defined at vol.
+func validVol(vol float64) bool {
+	return vol > 0 && !math.IsInf(vol, 1)
+}
+
+func (p params) d1d2(vol float64) (float64, float64) {
+	sqrtT := math.Sqrt(p.t)
+	d1 := (math.Log(p.spot/p.strike) + (p.rate-p.div+vol*vol/2)*p.t) / (vol * sqrtT)
+	return d1, d1 - vol*sqrtT
+}
+
+func (p params) price(vol float64) float64 {
+	d1, d2 := p.d1d2(vol)
+	df, qf := math.Exp(-p.rate*p.t), math.Exp(-p.div*p.t)
+
+	if p.typ == Call {
+		return p.spot*qf*cdf(d1) - p.strike*df*cdf(d2)
+	}
+
+	return p.strike*df*cdf(-d2) - p.spot*qf*cdf(-d1)
+}
+
+// vega is the derivative of the price with respect to vol.
+func (p params) vega(vol float64) float64 {
+	d1, _ := p.d1d2(vol)
+	return p.spot * math.Exp(-p.div*p.t) * pdf(d1) * math.Sqrt(p.t)
+}
+
+func (p params) greeks(vol float64) Greeks {
+	d1, d2 := p.d1d2(vol)
+	sqrtT := math.Sqrt(p.t)
+	df, qf := math.Exp(-p.rate*p.t), math.Exp(-p.div*p.t)
+	decay := -p.spot * qf * pdf(d1) * vol / (2 * sqrtT)
+
+	g := Greeks{
+		Gamma: qf * pdf(d1) / (p.spot * vol * sqrtT),
+		Vega:  p.vega(vol) / 100,
+	}
+
+	if p.typ == Call {
+		g.Delta = qf * cdf(d1)
+		g.Theta = decay - p.rate*p.strike*df*cdf(d2) + p.div*p.spot*qf*cdf(d1)
+		g.Rho = p.strike * p.t * df * cdf(d2) / 100
+	} else {
+		g.Delta = -qf * cdf(-d1)
+		g.Theta = decay + p.rate*p.strike*df*cdf(-d2) - p.div*p.spot*qf*cdf(-d1)
+		g.Rho = -p.strike * p.t * df * cdf(-d2) / 100
+	}
+	g.Theta /= TradingDaysPerYear
+
+	return g
+}
+
+// bounds returns the range of prices the model can yield: the discounted
+// intrinsic value and the discounted spot or strike.
+func (p params) bounds() (float64, float64) {
+	df, qf := math.Exp(-p.rate*p.t), math.Exp(-p.div*p.t)
+	if p.typ == Call {
+		return math.Max(p.spot*qf-p.strike*df, 0), p.spot * qf
+	}
+
+	return math.Max(p.strike*df-p.spot*qf, 0), p.strike * df
+}
+
+// impliedVol solves for the volatility which yields price, with Newton's
+// method from the Brenner-Subrahmanyam estimate, falling back to Brent's
+// method when Newton's steps leave the bracket or stall.
+func (p params) impliedVol(price float64) (float64, error) {
+	if err := p.validate(); err != nil {
+		return 0, err
+	}
+
+	lo, hi := p.bounds()
+	if math.IsNaN(price) || price <= lo || price >= hi {
+		return 0, ErrPriceOutOfBounds
+	}
+
+	f := func(vol float64) float64 { return p.price(vol) - price }
+
+	vol := math.Sqrt(2*math.Pi/p.t) * price / p.spot
+	if vol < minVol || vol > maxVol {
+		vol = 0.3
+	}
+
+	for i := 0; i < maxNewton; i++ {
+		diff := f(vol)
+		if math.Abs(diff) < volTolerance {
+			return vol, nil
+		}
+
+		v := p.vega(vol)
+		if v < 1e-12 {
+			break
+		}
+
+		next := vol - diff/v
+		if next <= minVol || next >= maxVol || math.IsNaN(next) {
+			break
+		}
+
+		if math.Abs(next-vol) < volTolerance {
+			return next, nil
+		}
+		vol = next
+	}
+
+	return brent(f, minVol, maxVol)
+}
+
+// brent finds a root of f in [a, b], between which f changes sign.
+func brent(f func(float64) float64, a, b float64) (float64, error) {
+	fa, fb := f(a), f(b)
+	if fa*fb > 0 {
+		return 0, ErrPriceOutOfBounds
+	}
+
+	if math.Abs(fa) < math.Abs(fb) {
+		a, b, fa, fb = b, a, fb, fa
+	}
+
+	c, fc := a, fa
+	d := b - a
+	bisected := true
+
+	for i := 0; i < maxBrent; i++ {
+		if fb == 0 || math.Abs(b-a) < volTolerance {
+			return b, nil
+		}
+
+		var s float64
+		if fa != fc && fb != fc {
+			// Inverse quadratic interpolation.
+			s = a*fb*fc/((fa-fb)*(fa-fc)) + b*fa*fc/((fb-fa)*(fb-fc)) + c*fa*fb/((fc-fa)*(fc-fb))
+		} else {
+			// Secant.
+			s = b - fb*(b-a)/(fb-fa)
+		}
+
+		if (s-(3*a+b)/4)*(s-b) >= 0 ||
+			(bisected && math.Abs(s-b) >= math.Abs(b-c)/2) ||
+			(!bisected && math.Abs(s-b) >= math.Abs(c-d)/2) ||
+			(bisected && math.Abs(b-c) < volTolerance) ||
+			(!bisected && math.Abs(c-d) < volTolerance) {
+			s = (a + b) / 2
+			bisected = true
+		} else {
+			bisected = false
+		}
+
+		fs := f(s)
+		d, c, fc = c, b, fb
+		if fa*fs < 0 {
+			b, fb = s, fs
+		} else {
+			a, fa = s, fs
+		}
+
+		if math.Abs(fa) < math.Abs(fb) {
+			a, b, fa, fb = b, a, fb, fa
+		}
+	}
+
+	return 0, ErrNoConvergence
+}
+
+// Price returns the Black-Scholes price of an option with the given spot
+// and strike prices, years to expiry t, rate, dividend yield and
+// volatility.
+func Price(typ OptionType, spot, strike, t, rate, div, vol float64) (float64, error) {
+	p := params{typ, spot, strike, t, rate, div}
+	if err := p.validate(); err != nil {
+		return 0, err
+	}
+
+	if !validVol(vol) {
+		return 0, ErrInvalidInput
+	}
+
+	return p.price(vol), nil
+}
+
+// ImpliedVol returns the volatility at which the Black-Scholes price of an
+// option is price.
+func ImpliedVol(typ OptionType, price, spot, strike, t, rate, div float64) (float64, error) {
+	return params{typ, spot, strike, t, rate, div}.impliedVol(price)
+}
+
+// Compute returns the greeks of an option at the given volatility.
+func Compute(typ OptionType, spot, strike, t, rate, div, vol float64) (Greeks, error) {
+	p := params{typ, spot, strike, t, rate, div}
+	if err := p.validate(); err != nil {
+		return Greeks{}, err
+	}
+
+	if !validVol(vol) {
+		return Greeks{}, ErrInvalidInput
+	}
+
+	return p.greeks(vol), nil
+}
+
+// cdf is the standard normal cumulative distribution function.
+func cdf(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+// pdf is the standard normal probability density function.
+func pdf(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
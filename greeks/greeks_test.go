@@ -0,0 +1,120 @@
+package greeks
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrice(t *testing.T) {
+	t.Parallel()
+
+	// Hull, Options, Futures and Other Derivatives, example 15.6.
+	c, err := Price(Call, 42, 40, 0.5, 0.1, 0, 0.2)
+	require.Nil(t, err)
+	assert.InDelta(t, 4.76, c, 0.005)
+
+	p, err := Price(Put, 42, 40, 0.5, 0.1, 0, 0.2)
+	require.Nil(t, err)
+	assert.InDelta(t, 0.81, p, 0.005)
+
+	// Put call parity with a dividend yield.
+	c, _ = Price(Call, 100, 95, 0.25, 0.07, 0.02, 0.3)
+	p, _ = Price(Put, 100, 95, 0.25, 0.07, 0.02, 0.3)
+	assert.InDelta(t, 100*math.Exp(-0.02*0.25)-95*math.Exp(-0.07*0.25), c-p, 1e-9)
+
+	_, err = Price(Call, 42, 40, 0, 0.1, 0, 0.2)
+	assert.Equal(t, ErrExpired, err)
+	_, err = Price("FUT", 42, 40, 0.5, 0.1, 0, 0.2)
+	assert.Equal(t, ErrInvalidInput, err)
+	_, err = Price(Call, 42, 40, math.NaN(), 0.1, 0, 0.2)
+	assert.Equal(t, ErrInvalidInput, err)
+
+	// The model is undefined without volatility.
+	for _, vol := range []float64{0, -0.2, math.NaN()} {
+		_, err = Price(Call, 42, 40, 0.5, 0.1, 0, vol)
+		assert.Equal(t, ErrInvalidInput, err)
+		_, err = Compute(Put, 42, 40, 0.5, 0.1, 0, vol)
+		assert.Equal(t, ErrInvalidInput, err)
+	}
+}
+
+func TestGreeks(t *testing.T) {
+	t.Parallel()
+
+	// Hull, examples 19.1, 19.4, 19.6 and 19.7.
+	g, err := Compute(Call, 49, 50, 0.3846, 0.05, 0, 0.2)
+	require.Nil(t, err)
+	assert.InDelta(t, 0.522, g.Delta, 0.001)
+	assert.InDelta(t, 0.066, g.Gamma, 0.001)
+	assert.InDelta(t, 0.121, g.Vega, 0.001)
+	assert.InDelta(t, 0.089, g.Rho, 0.001)
+	assert.InDelta(t, -4.31, g.Theta*TradingDaysPerYear, 0.01)
+
+	// Greeks agree with finite differences of the price.
+	const h = 1e-4
+	for _, typ := range []OptionType{Call, Put} {
+		for _, strike := range []float64{80, 100, 120} {
+			price := func(spot, t, rate, vol float64) float64 {
+				v, _ := Price(typ, spot, strike, t, rate, 0.01, vol)
+				return v
+			}
+
+			g, err := Compute(typ, 100, strike, 0.2, 0.07, 0.01, 0.25)
+			require.Nil(t, err)
+
+			assert.InDelta(t, (price(100+h, 0.2, 0.07, 0.25)-price(100-h, 0.2, 0.07, 0.25))/(2*h), g.Delta, 1e-6)
+			assert.InDelta(t, (price(100+h, 0.2, 0.07, 0.25)-2*price(100, 0.2, 0.07, 0.25)+price(100-h, 0.2, 0.07, 0.25))/(h*h), g.Gamma, 1e-3)
+			assert.InDelta(t, (price(100, 0.2, 0.07, 0.25+h)-price(100, 0.2, 0.07, 0.25-h))/(2*h)/100, g.Vega, 1e-6)
+			assert.InDelta(t, (price(100, 0.2, 0.07+h, 0.25)-price(100, 0.2, 0.07-h, 0.25))/(2*h)/100, g.Rho, 1e-6)
+			assert.InDelta(t, -(price(100, 0.2+h, 0.07, 0.25)-price(100, 0.2-h, 0.07, 0.25))/(2*h)/TradingDaysPerYear, g.Theta, 1e-6)
+		}
+	}
+}
+
+func TestImpliedVol(t *testing.T) {
+	t.Parallel()
+
+	for _, typ := range []OptionType{Call, Put} {
+		for _, strike := range []float64{50, 90, 100, 110, 200} {
+			for _, vol := range []float64{0.01, 0.12, 0.5, 2.5} {
+				for _, years := range []float64{1.0 / 252 / 375, 1.0 / 252, 0.1, 2} {
+					price, err := Price(typ, 100, strike, years, 0.07, 0.01, vol)
+					require.Nil(t, err)
+
+					lo, hi := params{typ, 100, strike, years, 0.07, 0.01}.bounds()
+					// Too close to the bounds for the price to pin down the volatility.
+					if price-lo < 1e-7 || hi-price < 1e-7 {
+						continue
+					}
+
+					iv, err := ImpliedVol(typ, price, 100, strike, years, 0.07, 0.01)
+					require.Nil(t, err, "%s %v %v %v", typ, strike, vol, years)
+
+					back, _ := Price(typ, 100, strike, years, 0.07, 0.01, iv)
+					assert.InDelta(t, price, back, 1e-7, "%s %v %v %v", typ, strike, vol, years)
+				}
+			}
+		}
+	}
+
+	// Below the intrinsic value.
+	_, err := ImpliedVol(Call, 1, 100, 90, 0.1, 0.07, 0)
+	assert.Equal(t, ErrPriceOutOfBounds, err)
+	_, err = ImpliedVol(Put, 200, 100, 90, 0.1, 0.07, 0)
+	assert.Equal(t, ErrPriceOutOfBounds, err)
+	_, err = ImpliedVol(Put, 5, 100, 90, 0, 0.07, 0)
+	assert.Equal(t, ErrExpired, err)
+}
+
+func TestBrent(t *testing.T) {
+	t.Parallel()
+	x, err := brent(func(x float64) float64 { return x*x*x - 2*x - 5 }, 2, 3)
+	require.Nil(t, err)
+	assert.InDelta(t, 2.0945514815, x, 1e-9)
+
+	_, err = brent(func(x float64) float64 { return x*x + 1 }, -1, 1)
+	assert.Equal(t, ErrPriceOutOfBounds, err)
+}
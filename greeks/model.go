@@ -0,0 +1,132 @@
+package greeks
+
+import (
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// Trading session of Indian equity derivatives, in IST.
+const (
+	sessionOpenHour    = 9
+	sessionOpenMinute  = 15
+	sessionCloseHour   = 15
+	sessionCloseMinute = 30
+	sessionMinutes     = 375
+
+	// TradingDaysPerYear is the number of trading days in the year
+	// Model.TimeToExpiry measures time in.
+	TradingDaysPerYear = 252
+)
+
+var ist = loadIST()
+
+func loadIST() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		return loc
+	}
+
+	return time.FixedZone("IST", 5*60*60+30*60)
+}
+
+// Model holds the market parameters options are valued with.
+type Model struct {
+	// Rate is the annualized risk-free rate.
+	Rate float64
+	// DividendYield is the annualized dividend yield of the underlying.
+	DividendYield float64
+	// Holidays are the days, as YYYY-MM-DD, on which the market is closed
+	// on a weekday. They don't count towards the time to expiry.
+	Holidays map[string]bool
+}
+
+// Result is the implied volatility and the greeks of an option.
+type Result struct {
+	Greeks
+	IV float64
+	// T is the time to expiry in years.
+	T float64
+}
+
+// TimeToExpiry returns the trading time from now until the close of the
+// session on the expiry day, in years of TradingDaysPerYear sessions. Only
+// the minutes the market is open count, so an option doesn't decay over
+// nights, weekends and holidays.
+func (m Model) TimeToExpiry(now, expiry time.Time) float64 {
+	now = now.In(ist)
+	e := expiry.In(ist)
+	end := time.Date(e.Year(), e.Month(), e.Day(), sessionCloseHour, sessionCloseMinute, 0, 0, ist)
+	if !now.Before(end) {
+		return 0
+	}
+
+	var minutes float64
+	for day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, ist); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !m.isTradingDay(day) {
+			continue
+		}
+
+		open := time.Date(day.Year(), day.Month(), day.Day(), sessionOpenHour, sessionOpenMinute, 0, 0, ist)
+		closing := open.Add(sessionMinutes * time.Minute)
+		if now.After(open) {
+			open = now
+		}
+
+		if closing.After(open) {
+			minutes += closing.Sub(open).Minutes()
+		}
+	}
+
+	return minutes / (sessionMinutes * TradingDaysPerYear)
+}
+
+func (m Model) isTradingDay(day time.Time) bool {
+	if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+
+	return !m.Holidays[day.Format("2006-01-02")]
+}
+
+// Price returns the price of an option at the given volatility.
+func (m Model) Price(typ OptionType, spot, strike, t, vol float64) (float64, error) {
+	return Price(typ, spot, strike, t, m.Rate, m.DividendYield, vol)
+}
+
+// ImpliedVol returns the implied volatility of an option.
+func (m Model) ImpliedVol(typ OptionType, price, spot, strike, t float64) (float64, error) {
+	return ImpliedVol(typ, price, spot, strike, t, m.Rate, m.DividendYield)
+}
+
+// Compute returns the implied volatility and the greeks at it of an option
+// instrument, from the last prices of the option and its underlying.
+func (m Model) Compute(inst kite.Instrument, optionPrice, underlyingPrice float64, now time.Time) (Result, error) {
+	typ := OptionType(inst.InstrumentType)
+	r := Result{T: m.TimeToExpiry(now, inst.Expiry.Time)}
+
+	iv, err := ImpliedVol(typ, optionPrice, underlyingPrice, inst.StrikePrice, r.T, m.Rate, m.DividendYield)
+	if err != nil {
+		return r, err
+	}
+	r.IV = iv
+
+	g, err := Compute(typ, underlyingPrice, inst.StrikePrice, r.T, m.Rate, m.DividendYield, iv)
+	if err != nil {
+		return r, err
+	}
+	r.Greeks = g
+
+	return r, nil
+}
+
+// ComputeTicks is Compute with the prices of ticks of the option and its
+// underlying. The time is taken from the option tick's exchange timestamp,
+// or the current time if it has none.
+func (m Model) ComputeTicks(inst kite.Instrument, option, underlying kite.Tick) (Result, error) {
+	now := option.Timestamp.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	return m.Compute(inst, option.LastPrice, underlying.LastPrice, now)
+}
@@ -0,0 +1,76 @@
+package greeks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeToExpiry(t *testing.T) {
+	t.Parallel()
+	m := Model{Holidays: map[string]bool{"2023-08-15": true}}
+	session := 1.0 / TradingDaysPerYear
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2023, month, day, hour, min, 0, 0, ist)
+	}
+	thu := at(7, 20, 0, 0)
+
+	cases := []struct {
+		now    time.Time
+		expiry time.Time
+		want   float64
+	}{
+		// Wednesday's close to Thursday's close.
+		{at(7, 19, 15, 30), thu, session},
+		// Before Thursday's open and at midday.
+		{at(7, 20, 8, 0), thu, session},
+		{at(7, 20, 12, 22).Add(30 * time.Second), thu, session / 2},
+		{at(7, 20, 15, 30), thu, 0},
+		{at(7, 21, 10, 0), thu, 0},
+		// Over a weekend.
+		{at(7, 14, 15, 30), at(7, 17, 0, 0), session},
+		// Over a weekend and a holiday, with a day and a half to go.
+		{at(8, 11, 12, 22).Add(30 * time.Second), at(8, 16, 0, 0), 2.5 * session},
+	}
+
+	for _, tc := range cases {
+		assert.InDelta(t, tc.want, m.TimeToExpiry(tc.now, tc.expiry), 1e-12, "%v", tc.now)
+	}
+
+	// Times in other zones are read in IST.
+	assert.InDelta(t, session, m.TimeToExpiry(time.Date(2023, 7, 19, 10, 0, 0, 0, time.UTC), thu), 1e-12)
+}
+
+func TestModelCompute(t *testing.T) {
+	t.Parallel()
+	m := Model{Rate: 0.07}
+	inst := kite.Instrument{
+		Tradingsymbol:  "NIFTY2372019500CE",
+		Expiry:         kite.Time{Time: time.Date(2023, 7, 20, 0, 0, 0, 0, ist)},
+		StrikePrice:    19500,
+		InstrumentType: "CE",
+	}
+	now := time.Date(2023, 7, 14, 15, 30, 0, 0, ist)
+
+	years := m.TimeToExpiry(now, inst.Expiry.Time)
+	assert.InDelta(t, 4.0/TradingDaysPerYear, years, 1e-12)
+
+	price, err := m.Price(Call, 19560, 19500, years, 0.11)
+	require.Nil(t, err)
+
+	option := kite.Tick{LastPrice: price, Timestamp: kite.Time{Time: now}}
+	underlying := kite.Tick{LastPrice: 19560}
+	r, err := m.ComputeTicks(inst, option, underlying)
+	require.Nil(t, err)
+	assert.InDelta(t, 0.11, r.IV, 1e-8)
+	assert.Equal(t, years, r.T)
+	assert.True(t, r.Delta > 0.5 && r.Delta < 1)
+	assert.True(t, r.Theta < 0)
+
+	inst.InstrumentType = "PE"
+	_, err = m.Compute(inst, 1, 19560, now.AddDate(0, 0, 7))
+	assert.Equal(t, ErrExpired, err)
+}
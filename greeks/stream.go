@@ -0,0 +1,178 @@
+package greeks
+
+import (
+	"sync"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// Update is the implied volatility and the greeks of an option, computed
+// from one of its ticks and the latest tick of its underlying.
+type Update struct {
+	Instrument kite.Instrument
+	Option     kite.Tick
+	Underlying kite.Tick
+	Result
+	// Err is set if the option couldn't be valued, as when its price is
+	// below the intrinsic value.
+	Err error
+}
+
+// Token returns the instrument token of the option.
+func (u Update) Token() uint32 {
+	return u.Option.InstrumentToken
+}
+
+type streamOption struct {
+	instrument kite.Instrument
+	underlying uint32
+}
+
+// Stream computes the implied volatility and the greeks of options as their
+// ticks arrive. Each option tick is paired with the latest tick of the
+// option's underlying, such as the index or the future, and an Update is
+// emitted. Option ticks which arrive before a tick of their underlying are
+// dropped.
+//
+//	s := greeks.NewStream(greeks.Model{Rate: 0.07})
+//	s.Add(inst, 256265) // NIFTY 50
+//	s.OnUpdate(func(u greeks.Update) { ... })
+//	ticker.OnTick(s.HandleTick)
+//	ticker.Subscribe(s.Tokens())
+type Stream struct {
+	model Model
+
+	mu          sync.RWMutex
+	options     map[uint32]streamOption
+	underlyings map[uint32]int
+	ticks       map[uint32]kite.Tick
+	latest      map[uint32]Update
+	onUpdate    func(Update)
+}
+
+// NewStream returns a stream which values options with m.
+func NewStream(m Model) *Stream {
+	return &Stream{
+		model:       m,
+		options:     make(map[uint32]streamOption),
+		underlyings: make(map[uint32]int),
+		ticks:       make(map[uint32]kite.Tick),
+		latest:      make(map[uint32]Update),
+	}
+}
+
+// OnUpdate sets the callback which receives every update. It is called on
+// the goroutine which handles the tick.
+func (s *Stream) OnUpdate(f func(Update)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onUpdate = f
+}
+
+// Add tracks an option instrument whose underlying has the instrument
+// token underlying.
+func (s *Stream) Add(inst kite.Instrument, underlying uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := uint32(inst.InstrumentToken)
+	if o, ok := s.options[token]; ok {
+		s.releaseUnderlying(o.underlying)
+	}
+
+	s.options[token] = streamOption{instrument: inst, underlying: underlying}
+	s.underlyings[underlying]++
+}
+
+// Remove stops tracking the option with the given instrument token.
+func (s *Stream) Remove(token uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.options[token]
+	if !ok {
+		return
+	}
+
+	delete(s.options, token)
+	delete(s.latest, token)
+	s.releaseUnderlying(o.underlying)
+}
+
+func (s *Stream) releaseUnderlying(token uint32) {
+	s.underlyings[token]--
+	if s.underlyings[token] <= 0 {
+		delete(s.underlyings, token)
+		delete(s.ticks, token)
+	}
+}
+
+// Tokens returns the instrument tokens of the options and their
+// underlyings, which the ticker needs to be subscribed to.
+func (s *Stream) Tokens() []uint32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]uint32, 0, len(s.options)+len(s.underlyings))
+	for t := range s.options {
+		tokens = append(tokens, t)
+	}
+
+	for t := range s.underlyings {
+		if _, ok := s.options[t]; !ok {
+			tokens = append(tokens, t)
+		}
+	}
+
+	return tokens
+}
+
+// Latest returns the last update of the option with the given instrument
+// token.
+func (s *Stream) Latest(token uint32) (Update, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.latest[token]
+	return u, ok
+}
+
+// HandleTick handles a tick of an option or an underlying. Ticks of other
+// instruments are ignored. It can be set as the ticker's OnTick callback.
+func (s *Stream) HandleTick(tick kite.Tick) {
+	s.mu.Lock()
+	if _, ok := s.underlyings[tick.InstrumentToken]; ok {
+		s.ticks[tick.InstrumentToken] = tick
+	}
+
+	o, ok := s.options[tick.InstrumentToken]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+
+	underlying, ok := s.ticks[o.underlying]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	u := Update{
+		Instrument: o.instrument,
+		Option:     tick,
+		Underlying: underlying,
+	}
+	u.Result, u.Err = s.model.ComputeTicks(o.instrument, tick, underlying)
+
+	s.mu.Lock()
+	// The option may have been removed meanwhile.
+	if _, ok := s.options[tick.InstrumentToken]; ok {
+		s.latest[tick.InstrumentToken] = u
+	}
+	onUpdate := s.onUpdate
+	s.mu.Unlock()
+
+	if onUpdate != nil {
+		onUpdate(u)
+	}
+}
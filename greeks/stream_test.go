@@ -0,0 +1,74 @@
+package greeks
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+	m := Model{Rate: 0.07}
+	s := NewStream(m)
+
+	expiry := kite.Time{Time: time.Date(2023, 7, 20, 0, 0, 0, 0, ist)}
+	call := kite.Instrument{InstrumentToken: 11, Expiry: expiry, StrikePrice: 19500, InstrumentType: "CE"}
+	put := kite.Instrument{InstrumentToken: 12, Expiry: expiry, StrikePrice: 19500, InstrumentType: "PE"}
+	s.Add(call, 256265)
+	s.Add(put, 256265)
+
+	tokens := s.Tokens()
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i] < tokens[j] })
+	require.Equal(t, []uint32{11, 12, 256265}, tokens)
+
+	var updates []Update
+	s.OnUpdate(func(u Update) { updates = append(updates, u) })
+
+	now := time.Date(2023, 7, 14, 15, 30, 0, 0, ist)
+	years := m.TimeToExpiry(now, expiry.Time)
+	callPrice, _ := m.Price(Call, 19560, 19500, years, 0.11)
+	putPrice, _ := m.Price(Put, 19560, 19500, years, 0.12)
+
+	// No underlying tick yet.
+	s.HandleTick(kite.Tick{InstrumentToken: 11, LastPrice: callPrice, Timestamp: kite.Time{Time: now}})
+	require.Equal(t, 0, len(updates))
+
+	s.HandleTick(kite.Tick{InstrumentToken: 256265, LastPrice: 19560})
+	require.Equal(t, 0, len(updates))
+
+	s.HandleTick(kite.Tick{InstrumentToken: 11, LastPrice: callPrice, Timestamp: kite.Time{Time: now}})
+	s.HandleTick(kite.Tick{InstrumentToken: 12, LastPrice: putPrice, Timestamp: kite.Time{Time: now}})
+	s.HandleTick(kite.Tick{InstrumentToken: 99, LastPrice: 1})
+	require.Equal(t, 2, len(updates))
+
+	require.Nil(t, updates[0].Err)
+	require.Equal(t, uint32(11), updates[0].Token())
+	assert.InDelta(t, 0.11, updates[0].IV, 1e-8)
+	assert.Equal(t, 19560.0, updates[0].Underlying.LastPrice)
+	assert.InDelta(t, 0.12, updates[1].IV, 1e-8)
+	assert.True(t, updates[1].Delta < 0)
+
+	latest, ok := s.Latest(12)
+	require.True(t, ok)
+	assert.Equal(t, updates[1].IV, latest.IV)
+
+	// Prices the model can't yield are reported.
+	s.HandleTick(kite.Tick{InstrumentToken: 11, LastPrice: 1, Timestamp: kite.Time{Time: now}})
+	require.Equal(t, ErrPriceOutOfBounds, updates[2].Err)
+
+	s.Remove(11)
+	_, ok = s.Latest(11)
+	require.False(t, ok)
+	require.Equal(t, 2, len(s.Tokens()))
+
+	// The underlying is released with its last option.
+	s.Remove(12)
+	require.Equal(t, 0, len(s.Tokens()))
+	s.Add(put, 256265)
+	s.HandleTick(kite.Tick{InstrumentToken: 12, LastPrice: putPrice, Timestamp: kite.Time{Time: now}})
+	require.Equal(t, 3, len(updates))
+}
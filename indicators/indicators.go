@@ -0,0 +1,76 @@
+// Package indicators computes technical indicators over candles, such as
+// those returned by GetHistoricalData or built by the candles package.
+//
+// Each indicator comes in a batch form, a function over a whole series
+// which returns a value per candle, and a streaming form, which takes one
+// candle at a time in O(1) and returns its value. Both give the same values.
+// Values are NaN until the indicator has seen enough candles. Periods less
+// than 1 are taken as 1.
+//
+//	rsi := indicators.RSI(indicators.Closes(data), 14)
+//
+//	s := indicators.NewRSIStream(14)
+//	aggregator.OnClose(func(token uint32, c kite.HistoricalData) {
+//		if v := s.Update(c.Close); v > 70 { ... }
+//	})
+package indicators
+
+import (
+	"math"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// Closes returns the closing prices of candles.
+func Closes(candles []kite.HistoricalData) []float64 {
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = c.Close
+	}
+
+	return out
+}
+
+// TypicalPrice returns the average of the high, low and close of a candle.
+func TypicalPrice(c kite.HistoricalData) float64 {
+	return (c.High + c.Low + c.Close) / 3
+}
+
+// window is a ring buffer of the last n values.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(n int) *window {
+	return &window{values: make([]float64, n)}
+}
+
+// push adds v and returns the value it evicted, or 0 if the window wasn't
+// full.
+func (w *window) push(v float64) float64 {
+	old, full := w.values[w.next], w.full
+	w.values[w.next] = v
+	w.next++
+	if w.next == len(w.values) {
+		w.next, w.full = 0, true
+	}
+
+	if !full {
+		return 0
+	}
+
+	return old
+}
+
+// period returns n, or 1 if n is less than 1.
+func period(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+var nan = math.NaN()
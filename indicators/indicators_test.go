@@ -0,0 +1,116 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+// testCandles are 30 candles, the first 15 of a day and the rest of the
+// next, with the reference values below computed from them independently.
+var testCandles = func() []kite.HistoricalData {
+	rows := [][5]float64{
+		{99.65, 100.3, 98.18, 98.25, 648},
+		{97.44, 98.68, 97.23, 97.77, 188},
+		{97.64, 97.73, 95.5, 95.92, 946},
+		{96.05, 98.47, 95.47, 97.84, 163},
+		{97.99, 98.97, 97.53, 97.58, 979},
+		{96.85, 97.39, 95.96, 96.53, 673},
+		{97.16, 97.74, 95.24, 95.88, 481},
+		{95.07, 96.48, 94.45, 95.92, 608},
+		{96.28, 96.59, 95.4, 95.99, 564},
+		{95.71, 95.89, 93.92, 94.7, 183},
+		{94.85, 95.83, 94.12, 94.95, 394},
+		{95.17, 95.68, 93.3, 93.46, 450},
+		{92.76, 92.8, 92.05, 92.72, 882},
+		{92.84, 94.82, 92.5, 94.0, 458},
+		{94.19, 94.97, 93.35, 94.51, 376},
+		{94.46, 95.18, 93.76, 95.12, 762},
+		{95.28, 96.45, 94.56, 96.0, 784},
+		{95.69, 97.81, 95.08, 97.45, 605},
+		{96.57, 97.77, 96.32, 97.64, 500},
+		{98.47, 98.64, 98.06, 98.46, 384},
+		{99.23, 101.37, 98.95, 100.51, 525},
+		{101.48, 102.59, 101.25, 102.21, 184},
+		{101.56, 101.79, 100.01, 100.49, 703},
+		{99.85, 100.0, 98.45, 98.98, 724},
+		{99.11, 101.61, 98.59, 100.92, 732},
+		{101.23, 102.65, 100.36, 102.19, 995},
+		{102.55, 103.19, 102.16, 102.79, 593},
+		{103.06, 103.13, 101.1, 101.31, 266},
+		{100.53, 101.03, 99.96, 100.93, 649},
+		{100.13, 100.16, 98.71, 99.58, 728},
+	}
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	candles := make([]kite.HistoricalData, len(rows))
+	for i, r := range rows {
+		t := time.Date(2023, 7, 3, 9, 15+i, 0, 0, ist)
+		if i >= 15 {
+			t = time.Date(2023, 7, 4, 9, i, 0, 0, ist)
+		}
+
+		candles[i] = kite.HistoricalData{
+			Date:   kite.Time{Time: t},
+			Open:   r[0],
+			High:   r[1],
+			Low:    r[2],
+			Close:  r[3],
+			Volume: int(r[4]),
+		}
+	}
+
+	return candles
+}()
+
+// requireValues checks values against reference ones rounded to 4
+// decimals, NaN where there is no value yet.
+func requireValues(t *testing.T, want, got []float64) {
+	require.Equal(t, len(want), len(got))
+	for i := range want {
+		if math.IsNaN(want[i]) {
+			require.True(t, math.IsNaN(got[i]), "value %d is %v, want NaN", i, got[i])
+			continue
+		}
+
+		require.InDelta(t, want[i], got[i], 1e-4, "value %d", i)
+	}
+}
+
+func TestCloses(t *testing.T) {
+	t.Parallel()
+	closes := Closes(testCandles)
+	require.Equal(t, 30, len(closes))
+	require.Equal(t, 98.25, closes[0])
+	require.Equal(t, 99.58, closes[29])
+	require.InDelta(t, (98.97+97.53+97.58)/3, TypicalPrice(testCandles[4]), 1e-9)
+}
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+	w := newWindow(3)
+	require.Equal(t, 0.0, w.push(1))
+	require.Equal(t, 0.0, w.push(2))
+	require.False(t, w.full)
+	require.Equal(t, 0.0, w.push(3))
+	require.True(t, w.full)
+	require.Equal(t, 1.0, w.push(4))
+	require.Equal(t, 2.0, w.push(5))
+
+}
+
+func TestNonPositivePeriod(t *testing.T) {
+	t.Parallel()
+	values := []float64{1, 3, 2, 5}
+
+	// Periods less than 1 are taken as 1.
+	require.Equal(t, values, SMA(values, 0))
+	require.Equal(t, values, EMA(values, -1))
+	require.Equal(t, []float64{100, 0, 100}, RSI(values, -1)[1:])
+
+	atr := ATR([]kite.HistoricalData{{High: 2, Low: 1}, {High: 4, Low: 3, Close: 3}}, 0)
+	require.Equal(t, []float64{1, 4}, atr)
+}
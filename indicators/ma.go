@@ -0,0 +1,156 @@
+package indicators
+
+import "math"
+
+// SMAStream is the simple moving average of the last n values.
+type SMAStream struct {
+	window *window
+	sum    float64
+	value  float64
+}
+
+// NewSMAStream returns the simple moving average of n values.
+func NewSMAStream(n int) *SMAStream {
+	n = period(n)
+	return &SMAStream{window: newWindow(n), value: nan}
+}
+
+// Update adds a value and returns the average.
+func (s *SMAStream) Update(v float64) float64 {
+	s.sum += v - s.window.push(v)
+	if s.window.full {
+		s.value = s.sum / float64(len(s.window.values))
+	}
+
+	return s.value
+}
+
+// Value returns the average as of the last update.
+func (s *SMAStream) Value() float64 {
+	return s.value
+}
+
+// SMA returns the simple moving average of n values.
+func SMA(values []float64, n int) []float64 {
+	s := NewSMAStream(n)
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = s.Update(v)
+	}
+
+	return out
+}
+
+// EMAStream is the exponential moving average of values with a smoothing
+// factor of 2/(n+1), seeded with the simple average of the first n values.
+type EMAStream struct {
+	n     int
+	alpha float64
+	count int
+	sum   float64
+	value float64
+}
+
+// NewEMAStream returns the exponential moving average of period n.
+func NewEMAStream(n int) *EMAStream {
+	n = period(n)
+	return newSmoothing(n, 2/float64(n+1))
+}
+
+// newSmoothing returns an exponential moving average with the smoothing
+// factor alpha, as Wilder's smoothing is one with 1/n. n must be at least 1.
+func newSmoothing(n int, alpha float64) *EMAStream {
+	return &EMAStream{n: n, alpha: alpha, value: nan}
+}
+
+// Update adds a value and returns the average.
+func (s *EMAStream) Update(v float64) float64 {
+	if s.count < s.n {
+		s.count++
+		s.sum += v
+		if s.count == s.n {
+			s.value = s.sum / float64(s.n)
+		}
+
+		return s.value
+	}
+
+	s.value += s.alpha * (v - s.value)
+	return s.value
+}
+
+// Value returns the average as of the last update.
+func (s *EMAStream) Value() float64 {
+	return s.value
+}
+
+// EMA returns the exponential moving average of period n.
+func EMA(values []float64, n int) []float64 {
+	s := NewEMAStream(n)
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = s.Update(v)
+	}
+
+	return out
+}
+
+// Bands are Bollinger Bands: a moving average and the bands a number of
+// standard deviations above and below it.
+type Bands struct {
+	Middle float64
+	Upper  float64
+	Lower  float64
+}
+
+// BollingerStream is the Bollinger Bands of the last n values, k population
+// standard deviations apart from their simple moving average.
+type BollingerStream struct {
+	window *window
+	k      float64
+	sum    float64
+	sumSq  float64
+	value  Bands
+}
+
+// NewBollingerStream returns the Bollinger Bands of n values, k standard
+// deviations wide, usually 20 and 2.
+func NewBollingerStream(n int, k float64) *BollingerStream {
+	n = period(n)
+	return &BollingerStream{window: newWindow(n), k: k, value: Bands{nan, nan, nan}}
+}
+
+// Update adds a value and returns the bands.
+func (s *BollingerStream) Update(v float64) Bands {
+	old := s.window.push(v)
+	s.sum += v - old
+	s.sumSq += v*v - old*old
+	if !s.window.full {
+		return s.value
+	}
+
+	n := float64(len(s.window.values))
+	mean := s.sum / n
+	// Rounding can leave the variance of equal values slightly negative.
+	sd := math.Sqrt(math.Max(s.sumSq/n-mean*mean, 0))
+	s.value = Bands{Middle: mean, Upper: mean + s.k*sd, Lower: mean - s.k*sd}
+
+	return s.value
+}
+
+// Value returns the bands as of the last update.
+func (s *BollingerStream) Value() Bands {
+	return s.value
+}
+
+// Bollinger returns the Bollinger Bands of n values, k standard deviations
+// wide.
+func Bollinger(values []float64, n int, k float64) []Bands {
+	s := NewBollingerStream(n, k)
+	out := make([]Bands, len(values))
+	for i, v := range values {
+		out[i] = s.Update(v)
+	}
+
+	return out
+}
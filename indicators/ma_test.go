@@ -0,0 +1,58 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMA(t *testing.T) {
+	t.Parallel()
+	want := []float64{nan, nan, nan, nan, 97.4720, 97.1280, 96.7500, 96.7500, 96.3800, 95.8040, 95.4880, 95.0040, 94.3640, 93.9660, 93.9280, 93.9620, 94.4700, 95.4160, 96.1440, 96.9340, 98.0120, 99.2540, 99.8620, 100.1300, 100.6220, 100.9580, 101.0740, 101.2380, 101.6280, 101.3600}
+	requireValues(t, want, SMA(Closes(testCandles), 5))
+
+	s := NewSMAStream(5)
+	require.True(t, math.IsNaN(s.Value()))
+	for _, c := range testCandles {
+		s.Update(c.Close)
+	}
+	require.InDelta(t, want[29], s.Value(), 1e-4)
+	require.Equal(t, []float64{1, 2, 3}, SMA([]float64{1, 2, 3}, 1))
+}
+
+func TestEMA(t *testing.T) {
+	t.Parallel()
+	want := []float64{nan, nan, nan, nan, 97.4720, 97.1580, 96.7320, 96.4613, 96.3042, 95.7695, 95.4963, 94.8175, 94.1184, 94.0789, 94.2226, 94.5217, 95.0145, 95.8263, 96.4309, 97.1073, 98.2415, 99.5643, 99.8729, 99.5753, 100.0235, 100.7457, 101.4271, 101.3881, 101.2354, 100.6836}
+	requireValues(t, want, EMA(Closes(testCandles), 5))
+
+	// The average of a constant is the constant.
+	for _, v := range EMA([]float64{7, 7, 7, 7, 7}, 2)[1:] {
+		require.Equal(t, 7.0, v)
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	t.Parallel()
+	bands := Bollinger(Closes(testCandles), 10, 2)
+	for _, b := range bands[:9] {
+		require.True(t, math.IsNaN(b.Middle) && math.IsNaN(b.Upper) && math.IsNaN(b.Lower))
+	}
+
+	want := []Bands{
+		{100.55, 103.8048, 97.2952},
+		{100.879, 103.4926, 98.2654},
+		{100.991, 103.2527, 98.7293},
+	}
+	for i, w := range want {
+		got := bands[27+i]
+		require.InDelta(t, w.Middle, got.Middle, 1e-4)
+		require.InDelta(t, w.Upper, got.Upper, 1e-4)
+		require.InDelta(t, w.Lower, got.Lower, 1e-4)
+	}
+
+	// Equal values have no deviation.
+	b := Bollinger([]float64{0.1, 0.1, 0.1}, 3, 2)[2]
+	require.InDelta(t, 0.1, b.Upper, 1e-12)
+	require.InDelta(t, 0.1, b.Lower, 1e-12)
+}
@@ -0,0 +1,134 @@
+package indicators
+
+import "math"
+
+// RSIStream is Wilder's relative strength index of period n: 100 - 100 /
+// (1 + RS), where RS is the ratio of the smoothed average gain of values to
+// their smoothed average loss.
+type RSIStream struct {
+	gain  *EMAStream
+	loss  *EMAStream
+	prev  float64
+	count int
+	value float64
+}
+
+// NewRSIStream returns the relative strength index of period n, usually
+// 14.
+func NewRSIStream(n int) *RSIStream {
+	n = period(n)
+	return &RSIStream{
+		gain:  newSmoothing(n, 1/float64(n)),
+		loss:  newSmoothing(n, 1/float64(n)),
+		value: nan,
+	}
+}
+
+// Update adds a value and returns the index. The first value only sets the
+// base of the first change, so the index starts with the n+1-th value.
+func (s *RSIStream) Update(v float64) float64 {
+	s.count++
+	prev := s.prev
+	s.prev = v
+	if s.count == 1 {
+		return s.value
+	}
+
+	change := v - prev
+	var gain, loss float64
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	avgGain, avgLoss := s.gain.Update(gain), s.loss.Update(loss)
+	switch {
+	case math.IsNaN(avgGain):
+		// Not enough changes yet.
+	case avgLoss == 0 && avgGain == 0:
+		s.value = 50
+	case avgLoss == 0:
+		s.value = 100
+	default:
+		s.value = 100 - 100/(1+avgGain/avgLoss)
+	}
+
+	return s.value
+}
+
+// Value returns the index as of the last update.
+func (s *RSIStream) Value() float64 {
+	return s.value
+}
+
+// RSI returns the relative strength index of period n.
+func RSI(values []float64, n int) []float64 {
+	s := NewRSIStream(n)
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = s.Update(v)
+	}
+
+	return out
+}
+
+// MACDValue is the moving average convergence divergence: the difference
+// of a fast and a slow moving average, its signal line and their
+// difference.
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACDStream is the moving average convergence divergence of values.
+type MACDStream struct {
+	fast   *EMAStream
+	slow   *EMAStream
+	signal *EMAStream
+	value  MACDValue
+}
+
+// NewMACDStream returns the moving average convergence divergence of the
+// exponential moving averages of periods fast and slow, with a signal line
+// of period signal, usually 12, 26 and 9.
+func NewMACDStream(fast, slow, signal int) *MACDStream {
+	return &MACDStream{
+		fast:   NewEMAStream(fast),
+		slow:   NewEMAStream(slow),
+		signal: NewEMAStream(signal),
+		value:  MACDValue{nan, nan, nan},
+	}
+}
+
+// Update adds a value and returns the MACD. The signal line starts once
+// there are signal MACD values.
+func (s *MACDStream) Update(v float64) MACDValue {
+	fast, slow := s.fast.Update(v), s.slow.Update(v)
+	if math.IsNaN(fast) || math.IsNaN(slow) {
+		return s.value
+	}
+
+	macd := fast - slow
+	signal := s.signal.Update(macd)
+	s.value = MACDValue{MACD: macd, Signal: signal, Histogram: macd - signal}
+
+	return s.value
+}
+
+// Value returns the MACD as of the last update.
+func (s *MACDStream) Value() MACDValue {
+	return s.value
+}
+
+// MACD returns the moving average convergence divergence of values.
+func MACD(values []float64, fast, slow, signal int) []MACDValue {
+	s := NewMACDStream(fast, slow, signal)
+	out := make([]MACDValue, len(values))
+	for i, v := range values {
+		out[i] = s.Update(v)
+	}
+
+	return out
+}
@@ -0,0 +1,58 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSI(t *testing.T) {
+	t.Parallel()
+	// Wilder's example, as used by StockCharts, which rounds the averages
+	// and so differs in the second decimal.
+	closes := []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+		46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+		43.42, 42.66, 43.13,
+	}
+	want := []float64{
+		70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34,
+		54.67, 50.39, 40.02, 41.49, 41.90, 45.50, 37.32, 33.09, 37.79,
+	}
+
+	got := RSI(closes, 14)
+	for _, v := range got[:14] {
+		require.True(t, math.IsNaN(v))
+	}
+
+	for i, w := range want {
+		require.InDelta(t, w, got[14+i], 0.005, "value %d", 14+i)
+	}
+
+	require.Equal(t, 100.0, RSI([]float64{1, 2, 3}, 2)[2])
+	require.Equal(t, 50.0, RSI([]float64{1, 1, 1}, 2)[2])
+	require.Equal(t, 0.0, RSI([]float64{3, 2, 1}, 2)[2])
+}
+
+func TestMACD(t *testing.T) {
+	t.Parallel()
+	wantMACD := []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, -0.8685, -0.8348, -0.9915, -1.1291, -0.9417, -0.7052, -0.4410, -0.1368, 0.2571, 0.4851, 0.7044, 1.0919, 1.4946, 1.3631, 0.9800, 1.0056, 1.1510, 1.2515, 1.0062, 0.7538, 0.3660}
+	wantSignal := []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, -0.9560, -0.9503, -0.8522, -0.6877, -0.4674, -0.1776, 0.0875, 0.3342, 0.6373, 0.9802, 1.1334, 1.0720, 1.0454, 1.0877, 1.1532, 1.0944, 0.9582, 0.7213}
+
+	s := NewMACDStream(5, 10, 4)
+	macd, signal, hist := make([]float64, 30), make([]float64, 30), make([]float64, 30)
+	for i, c := range testCandles {
+		v := s.Update(c.Close)
+		macd[i], signal[i], hist[i] = v.MACD, v.Signal, v.Histogram
+	}
+
+	requireValues(t, wantMACD, macd)
+	requireValues(t, wantSignal, signal)
+	for i := 12; i < 30; i++ {
+		require.InDelta(t, macd[i]-signal[i], hist[i], 1e-12)
+	}
+
+	require.Equal(t, s.Value(), MACD(Closes(testCandles), 5, 10, 4)[29])
+}
@@ -0,0 +1,139 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// ATRStream is Wilder's average true range of period n. The true range of
+// a candle is its range extended to the previous close; that of the first
+// candle is its range.
+type ATRStream struct {
+	avg       *EMAStream
+	prevClose float64
+	started   bool
+}
+
+// NewATRStream returns the average true range of period n, usually 14.
+func NewATRStream(n int) *ATRStream {
+	n = period(n)
+	return &ATRStream{avg: newSmoothing(n, 1/float64(n))}
+}
+
+// Update adds a candle and returns the average true range, which starts
+// with the n-th candle.
+func (s *ATRStream) Update(c kite.HistoricalData) float64 {
+	tr := c.High - c.Low
+	if s.started {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-s.prevClose), math.Abs(c.Low-s.prevClose)))
+	}
+	s.prevClose, s.started = c.Close, true
+
+	return s.avg.Update(tr)
+}
+
+// Value returns the average true range as of the last update.
+func (s *ATRStream) Value() float64 {
+	return s.avg.Value()
+}
+
+// ATR returns the average true range of period n.
+func ATR(candles []kite.HistoricalData, n int) []float64 {
+	s := NewATRStream(n)
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+
+	return out
+}
+
+// SupertrendValue is the Supertrend line and the trend it follows. In an
+// uptrend the line is below the price, and in a downtrend above it.
+type SupertrendValue struct {
+	Value float64
+	Up    bool
+}
+
+// SupertrendStream is the Supertrend of period n: bands a multiple of the
+// average true range above and below the middle of the candles' ranges,
+// which only move in the direction of the trend. The trend turns when a
+// candle closes beyond the band on the other side of the price.
+type SupertrendStream struct {
+	atr        *ATRStream
+	multiplier float64
+	upper      float64
+	lower      float64
+	prevClose  float64
+	started    bool
+	value      SupertrendValue
+}
+
+// NewSupertrendStream returns the Supertrend of period n with the bands
+// multiplier average true ranges away, usually 10 and 3.
+func NewSupertrendStream(n int, multiplier float64) *SupertrendStream {
+	return &SupertrendStream{
+		atr:        NewATRStream(n),
+		multiplier: multiplier,
+		value:      SupertrendValue{Value: nan},
+	}
+}
+
+// Update adds a candle and returns the Supertrend, which starts with the
+// n-th candle, in an uptrend if it closes in the upper half of its range.
+func (s *SupertrendStream) Update(c kite.HistoricalData) SupertrendValue {
+	atr := s.atr.Update(c)
+	prevClose := s.prevClose
+	s.prevClose = c.Close
+	if math.IsNaN(atr) {
+		return s.value
+	}
+
+	mid := (c.High + c.Low) / 2
+	upper, lower := mid+s.multiplier*atr, mid-s.multiplier*atr
+	if !s.started {
+		s.upper, s.lower, s.started = upper, lower, true
+		s.value.Up = c.Close >= mid
+	} else {
+		// The bands only tighten, unless the previous close crossed them.
+		if upper < s.upper || prevClose > s.upper {
+			s.upper = upper
+		}
+
+		if lower > s.lower || prevClose < s.lower {
+			s.lower = lower
+		}
+
+		if s.value.Up && c.Close < s.lower {
+			s.value.Up = false
+		} else if !s.value.Up && c.Close > s.upper {
+			s.value.Up = true
+		}
+	}
+
+	if s.value.Up {
+		s.value.Value = s.lower
+	} else {
+		s.value.Value = s.upper
+	}
+
+	return s.value
+}
+
+// Value returns the Supertrend as of the last update.
+func (s *SupertrendStream) Value() SupertrendValue {
+	return s.value
+}
+
+// Supertrend returns the Supertrend of period n with the bands multiplier
+// average true ranges away.
+func Supertrend(candles []kite.HistoricalData, n int, multiplier float64) []SupertrendValue {
+	s := NewSupertrendStream(n, multiplier)
+	out := make([]SupertrendValue, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+
+	return out
+}
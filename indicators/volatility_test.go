@@ -0,0 +1,44 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestATR(t *testing.T) {
+	t.Parallel()
+	want := []float64{nan, nan, nan, nan, 2.0560, 1.9688, 2.0750, 2.0660, 1.8908, 1.9267, 1.8833, 1.9827, 1.8681, 1.9585, 1.8908, 1.7966, 1.8153, 1.9983, 1.8886, 1.7109, 1.9507, 1.9766, 2.0213, 2.0250, 2.2240, 2.2372, 1.9958, 2.0026, 1.8721, 1.9417}
+	requireValues(t, want, ATR(testCandles, 5))
+
+	s := NewATRStream(5)
+	for _, c := range testCandles {
+		s.Update(c)
+	}
+	require.InDelta(t, want[29], s.Value(), 1e-4)
+}
+
+func TestSupertrend(t *testing.T) {
+	t.Parallel()
+	want := []SupertrendValue{{nan, false}, {nan, false}, {nan, false}, {nan, false}, {102.3620, false}, {100.6126, false}, {100.6126, false}, {99.5971, false}, {99.5971, false}, {98.7583, false}, {98.7417, false}, {98.4553, false}, {96.1613, false}, {96.1613, false}, {96.1613, false}, {96.1613, false}, {96.1613, false}, {92.4485, true}, {93.2678, true}, {94.9282, true}, {96.2586, true}, {97.9669, true}, {97.9669, true}, {97.9669, true}, {97.9669, true}, {97.9669, true}, {98.6835, true}, {98.6835, true}, {98.6835, true}, {98.6835, true}}
+
+	got := Supertrend(testCandles, 5, 2)
+	require.Equal(t, len(want), len(got))
+	for i, w := range want {
+		if math.IsNaN(w.Value) {
+			require.True(t, math.IsNaN(got[i].Value), "value %d", i)
+			continue
+		}
+
+		require.InDelta(t, w.Value, got[i].Value, 1e-4, "value %d", i)
+		require.Equal(t, w.Up, got[i].Up, "value %d", i)
+	}
+
+	// The downtrend's band tightens until the rally closes above it, and
+	// the line flips below the price.
+	require.False(t, got[16].Up)
+	require.True(t, got[17].Up)
+	require.Greater(t, got[16].Value, testCandles[16].Close)
+	require.Less(t, got[17].Value, testCandles[17].Close)
+}
@@ -0,0 +1,103 @@
+package indicators
+
+import (
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// VWAPStream is the volume weighted average typical price of the candles
+// of the day, which starts over on each day.
+type VWAPStream struct {
+	day    time.Time
+	pv     float64
+	volume float64
+	value  float64
+}
+
+// NewVWAPStream returns the volume weighted average price.
+func NewVWAPStream() *VWAPStream {
+	return &VWAPStream{value: nan}
+}
+
+// Update adds a candle and returns the average price of the day. Days are
+// those of the candles' times in their location, which is IST for candles
+// returned by Kite. The average is NaN until a candle of the day has
+// volume.
+func (s *VWAPStream) Update(c kite.HistoricalData) float64 {
+	y, m, d := c.Date.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if !day.Equal(s.day) {
+		s.day, s.pv, s.volume, s.value = day, 0, 0, nan
+	}
+
+	s.pv += TypicalPrice(c) * float64(c.Volume)
+	s.volume += float64(c.Volume)
+	if s.volume > 0 {
+		s.value = s.pv / s.volume
+	}
+
+	return s.value
+}
+
+// Value returns the average price as of the last update.
+func (s *VWAPStream) Value() float64 {
+	return s.value
+}
+
+// VWAP returns the volume weighted average price of candles, which starts
+// over on each day.
+func VWAP(candles []kite.HistoricalData) []float64 {
+	s := NewVWAPStream()
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+
+	return out
+}
+
+// OBVStream is the on-balance volume: the running total of the candles'
+// volumes, added when a candle closes above the previous one and
+// subtracted when it closes below.
+type OBVStream struct {
+	prevClose float64
+	started   bool
+	value     float64
+}
+
+// NewOBVStream returns the on-balance volume, which starts at 0.
+func NewOBVStream() *OBVStream {
+	return &OBVStream{}
+}
+
+// Update adds a candle and returns the on-balance volume.
+func (s *OBVStream) Update(c kite.HistoricalData) float64 {
+	if s.started {
+		switch {
+		case c.Close > s.prevClose:
+			s.value += float64(c.Volume)
+		case c.Close < s.prevClose:
+			s.value -= float64(c.Volume)
+		}
+	}
+	s.prevClose, s.started = c.Close, true
+
+	return s.value
+}
+
+// Value returns the on-balance volume as of the last update.
+func (s *OBVStream) Value() float64 {
+	return s.value
+}
+
+// OBV returns the on-balance volume of candles.
+func OBV(candles []kite.HistoricalData) []float64 {
+	s := NewOBVStream()
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+
+	return out
+}
@@ -0,0 +1,33 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVWAP(t *testing.T) {
+	t.Parallel()
+	// The average starts over with the second day at the 16th candle.
+	want := []float64{98.9100, 98.6814, 97.4614, 97.4445, 97.6394, 97.4500, 97.3127, 97.0927, 96.9746, 96.9026, 96.7717, 96.5835, 96.0833, 95.9444, 95.8659, 94.6867, 95.1853, 95.6339, 95.9374, 96.2473, 96.8415, 97.0959, 97.6756, 97.8811, 98.1902, 98.7013, 99.0189, 99.1158, 99.2335, 99.2534}
+	requireValues(t, want, VWAP(testCandles))
+	require.Equal(t, TypicalPrice(testCandles[15]), VWAP(testCandles)[15])
+
+	s := NewVWAPStream()
+	require.True(t, math.IsNaN(s.Update(kite.HistoricalData{Date: testCandles[0].Date, Close: 10})))
+	require.Equal(t, 10.0, s.Update(kite.HistoricalData{Date: testCandles[1].Date, High: 10, Low: 10, Close: 10, Volume: 5}))
+}
+
+func TestOBV(t *testing.T) {
+	t.Parallel()
+	want := []float64{0.0000, -188.0000, -1134.0000, -971.0000, -1950.0000, -2623.0000, -3104.0000, -2496.0000, -1932.0000, -2115.0000, -1721.0000, -2171.0000, -3053.0000, -2595.0000, -2219.0000, -1457.0000, -673.0000, -68.0000, 432.0000, 816.0000, 1341.0000, 1525.0000, 822.0000, 98.0000, 830.0000, 1825.0000, 2418.0000, 2152.0000, 1503.0000, 775.0000}
+	requireValues(t, want, OBV(testCandles))
+
+	s := NewOBVStream()
+	s.Update(kite.HistoricalData{Close: 10, Volume: 100})
+	require.Equal(t, 0.0, s.Update(kite.HistoricalData{Close: 10, Volume: 100}))
+	require.Equal(t, 50.0, s.Update(kite.HistoricalData{Close: 11, Volume: 50}))
+	require.Equal(t, 50.0, s.Value())
+}
@@ -0,0 +1,100 @@
+package kite
+
+import (
+	"context"
+	"fmt"
+)
+
+// Backend identifies the API server a request is sent to.
+type Backend int
+
+const (
+	// BackendConnect is the Kite Connect API at api.kite.trade.
+	BackendConnect Backend = iota
+	// BackendOMS is the web OMS used by kite.zerodha.com. The same
+	// endpoints are served under the /oms path.
+	BackendOMS
+)
+
+const omsPathPrefix = "/oms"
+
+// String returns the name of the backend.
+func (b Backend) String() string {
+	switch b {
+	case BackendConnect:
+		return "connect"
+	case BackendOMS:
+		return "oms"
+	}
+
+	return fmt.Sprintf("Backend(%d)", int(b))
+}
+
+type backendCtxKey struct{}
+
+// WithBackend returns a copy of ctx which routes requests made with it to
+// backend b, overriding the client's default backend.
+//
+//	orders, err := kc.GetOrdersWithContext(kite.WithBackend(ctx, kite.BackendOMS))
+func WithBackend(ctx context.Context, b Backend) context.Context {
+	return context.WithValue(ctx, backendCtxKey{}, b)
+}
+
+// backendFromContext returns the backend set on ctx with WithBackend.
+func backendFromContext(ctx context.Context) (Backend, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	b, ok := ctx.Value(backendCtxKey{}).(Backend)
+	return b, ok
+}
+
+// backend returns the backend a request made with ctx is sent to.
+func (c *Client) backend(ctx context.Context) Backend {
+	if b, ok := backendFromContext(ctx); ok {
+		return b
+	}
+
+	return c.defaultBackend
+}
+
+// requestURL returns the absolute URL of uri on backend b.
+func (c *Client) requestURL(b Backend, uri string) string {
+	if b == BackendOMS {
+		return c.omsBaseURI + omsPathPrefix + uri
+	}
+
+	return c.baseURI + uri
+}
+
+// AuthScheme is the Authorization header scheme used for Connect API requests.
+type AuthScheme int
+
+const (
+	// AuthSchemeEnctoken sends `enctoken <access_token>`, the session token
+	// of the Kite web login.
+	AuthSchemeEnctoken AuthScheme = iota
+	// AuthSchemeToken sends `token <api_key>:<access_token>`, the session
+	// token of the official Kite Connect login flow.
+	AuthSchemeToken
+)
+
+// authHeader returns the Authorization header value for backend b. The OMS
+// only understands the enctoken scheme.
+func (c *Client) authHeader(b Backend) (string, error) {
+	accessToken, err := c.getAccessToken()
+	if accessToken == "" {
+		return "", err
+	}
+
+	c.tokenMu.Lock()
+	scheme := c.authScheme
+	c.tokenMu.Unlock()
+
+	if b == BackendConnect && scheme == AuthSchemeToken {
+		return fmt.Sprintf("token %s:%s", c.apiKey, accessToken), nil
+	}
+
+	return fmt.Sprintf("enctoken %s", accessToken), nil
+}
@@ -0,0 +1,97 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pathServer records the paths it was asked for.
+func pathServer() (*httptest.Server, func() []string) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+
+	return ts, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestBackendRouting(t *testing.T) {
+	t.Parallel()
+	connect, connectPaths := pathServer()
+	defer connect.Close()
+	oms, omsPaths := pathServer()
+	defer oms.Close()
+
+	kc := New("test")
+	kc.SetRateLimiter(nil)
+	kc.SetBaseURI(connect.URL)
+	kc.SetOMSBaseURI(oms.URL)
+
+	_, err := kc.GetOrdersWithContext(WithBackend(context.Background(), BackendOMS))
+	require.Nil(t, err)
+
+	// An OMS call must not change where later calls go.
+	_, err = kc.GetOrders()
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"/oms/orders"}, omsPaths())
+	require.Equal(t, []string{"/orders"}, connectPaths())
+
+	kc.SetBackend(BackendOMS)
+	_, err = kc.GetTrades()
+	require.Nil(t, err)
+	_, err = kc.GetTradesWithContext(WithBackend(context.Background(), BackendConnect))
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"/oms/orders", "/oms/trades"}, omsPaths())
+	require.Equal(t, []string{"/orders", "/trades"}, connectPaths())
+}
+
+func TestBackendConcurrentCalls(t *testing.T) {
+	t.Parallel()
+	connect, connectPaths := pathServer()
+	defer connect.Close()
+	oms, omsPaths := pathServer()
+	defer oms.Close()
+
+	kc := New("test")
+	kc.SetRateLimiter(nil)
+	kc.SetBaseURI(connect.URL)
+	kc.SetOMSBaseURI(oms.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			kc.GetOrdersWithContext(WithBackend(context.Background(), BackendOMS))
+		}()
+		go func() {
+			defer wg.Done()
+			kc.GetOrders()
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 10, len(omsPaths()))
+	require.Equal(t, 10, len(connectPaths()))
+	for _, p := range connectPaths() {
+		require.Equal(t, URIGetOrders, p)
+	}
+}
@@ -1,10 +1,11 @@
 package kite
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,10 +18,27 @@ type PlainResponse struct {
 
 // Client represents interface for Kite Connect client.
 type Client struct {
-	accessToken string
-	debug       bool
-	baseURI     string
-	httpClient  HTTPClient
+	apiKey         string
+	accessToken    string
+	authScheme     AuthScheme
+	debug          bool
+	baseURI        string
+	omsBaseURI     string
+	defaultBackend Backend
+	httpClient     HTTPClient
+	retryPolicy    RetryPolicy
+	limiter        *RateLimiter
+	middlewares    []Middleware
+
+	tokenStore     TokenStore
+	onTokenExpired func(Error)
+
+	// tokenMu guards accessToken and authScheme, which a new session
+	// replaces while requests read them, and expiredToken.
+	tokenMu sync.Mutex
+	// expiredToken is the expired stored token the token expired callback
+	// was last fired for.
+	expiredToken string
 }
 
 const (
@@ -29,6 +47,7 @@ const (
 	requestTimeout time.Duration = 7000 * time.Millisecond
 	baseURI        string        = "https://api.kite.trade"
 	kiteBaseURI    string        = "https://kite.zerodha.com"
+	loginURI       string        = "https://kite.zerodha.com/connect/login?api_key=%s&v=3"
 	// Kite connect header version
 	kiteHeaderVersion string = "3"
 )
@@ -85,6 +104,16 @@ const (
 	OrderStatusComplete  = "COMPLETE"
 	OrderStatusRejected  = "REJECTED"
 	OrderStatusCancelled = "CANCELLED"
+
+	// Candle intervals
+	IntervalMinute   = "minute"
+	Interval3Minute  = "3minute"
+	Interval5Minute  = "5minute"
+	Interval10Minute = "10minute"
+	Interval15Minute = "15minute"
+	Interval30Minute = "30minute"
+	Interval60Minute = "60minute"
+	IntervalDay      = "day"
 )
 
 // API endpoints
@@ -94,12 +123,10 @@ const (
 	URIUserSessionRenew      string = "/session/refresh_token"
 	URIUserProfile           string = "/user/profile"
 	URIUserMargins           string = "/user/margins"
-	URIUserMarginsOMS        string = "/oms/user/margins"
 	URIUserMarginsSegment    string = "/user/margins/%s" // "/user/margins/{segment}"
 
 	URIGetOrders       string = "/orders"
-	URIPlaceCharges    string = "/oms/charges/orders"
-	URIGetOMSOrders    string = "/oms/orders"
+	URIPlaceCharges    string = "/charges/orders"
 	URIGetTrades       string = "/trades"
 	URIGetOrderHistory string = "/orders/%s"        // "/orders/{order_id}"
 	URIGetOrderTrades  string = "/orders/%s/trades" // "/orders/{order_id}/trades"
@@ -108,14 +135,12 @@ const (
 	URICancelOrder     string = "/orders/%s/%s"     // "/orders/{variety}/{order_id}"
 
 	URIGetPositions     string = "/portfolio/positions"
-	URIGetPositionsOMS  string = "/oms/portfolio/positions"
 	URIGetHoldings      string = "/portfolio/holdings"
 	URIInitHoldingsAuth string = "/portfolio/holdings/authorise"
 	URIConvertPosition  string = "/portfolio/positions"
 
-	URIOrderMargins    string = "/margins/orders"
-	URIOrderMarginsOMS string = "/oms/margins/orders"
-	URIBasketMargins   string = "/margins/basket"
+	URIOrderMargins  string = "/margins/orders"
+	URIBasketMargins string = "/margins/basket"
 
 	// MF endpoints
 	URIGetMFOrders      string = "/mf/orders"
@@ -144,10 +169,9 @@ const (
 	URIGetHistorical          string = "/instruments/historical/%d/%s"    // "/instruments/historical/{instrument_token}/{interval}"
 	URIGetTriggerRange        string = "/instruments/%s/%s/trigger_range" // "/instruments/{exchange}/{tradingsymbol}/trigger_range"
 
-	URIGetQuote    string = "/quote"
-	URIGetQuoteOMS string = "/oms/quote"
-	URIGetLTP      string = "/quote/ltp"
-	URIGetOHLC     string = "/quote/ohlc"
+	URIGetQuote string = "/quote"
+	URIGetLTP   string = "/quote/ltp"
+	URIGetOHLC  string = "/quote/ohlc"
 )
 
 // New creates a new Kite Connect client.
@@ -155,6 +179,9 @@ func New(accessToken string) *Client {
 	client := &Client{
 		accessToken: accessToken,
 		baseURI:     baseURI,
+		omsBaseURI:  kiteBaseURI,
+		retryPolicy: DefaultRetryPolicy(),
+		limiter:     NewRateLimiter(DefaultRateLimits()),
 	}
 
 	// Create a default http handler with default timeout.
@@ -169,6 +196,22 @@ func New(accessToken string) *Client {
 // This can be used to set custom timeouts and transport.
 func (c *Client) SetHTTPClient(h *http.Client) {
 	c.httpClient = NewHTTPClient(h, nil, c.debug)
+	c.httpClient.GetClient().SetRetryPolicy(c.retryPolicy)
+	c.httpClient.GetClient().Use(c.middlewares...)
+}
+
+// Use adds middlewares to the HTTP request chain, for instance to log,
+// time or add headers to every request.
+func (c *Client) Use(mws ...Middleware) {
+	c.middlewares = append(c.middlewares, mws...)
+	c.httpClient.GetClient().Use(mws...)
+}
+
+// SetRetryPolicy sets the policy used to retry failed requests. Use
+// NoRetryPolicy to disable retries.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retryPolicy = p
+	c.httpClient.GetClient().SetRetryPolicy(p)
 }
 
 // SetDebug sets debug mode to enable HTTP logs.
@@ -182,79 +225,223 @@ func (c *Client) SetBaseURI(baseURI string) {
 	c.baseURI = baseURI
 }
 
+// SetOMSBaseURI overrides the base url of the web OMS backend. Requests
+// are sent to this url suffixed with /oms.
+func (c *Client) SetOMSBaseURI(baseURI string) {
+	c.omsBaseURI = baseURI
+}
+
+// SetBackend sets the backend requests are sent to by default. It can be
+// overridden per call with WithBackend.
+func (c *Client) SetBackend(b Backend) {
+	c.defaultBackend = b
+}
+
 // SetTimeout sets request timeout for default http client.
 func (c *Client) SetTimeout(timeout time.Duration) {
 	hClient := c.httpClient.GetClient().client
 	hClient.Timeout = timeout
 }
 
+// SetRateLimiter sets the limiter used to throttle requests. Passing nil
+// disables rate limiting.
+func (c *Client) SetRateLimiter(l *RateLimiter) {
+	c.limiter = l
+}
+
+// RateLimitStats returns the rate limiter counters per endpoint class.
+func (c *Client) RateLimitStats() map[EndpointClass]RateLimitStats {
+	if c.limiter == nil {
+		return nil
+	}
+
+	return c.limiter.Stats()
+}
+
 // SetAccessToken sets the access token to the Kite Connect instance.
+// If a token store is set, the token is saved to it as well; errors saving
+// it are ignored.
 func (c *Client) SetAccessToken(accessToken string) {
+	c.setAccessToken(accessToken)
+	c.saveToken(NewToken(accessToken))
+}
+
+func (c *Client) setAccessToken(accessToken string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
 	c.accessToken = accessToken
 }
 
-func (c *Client) doEnvelope(method, uri string, params url.Values, headers http.Header, v interface{}) error {
-	if params == nil {
-		params = url.Values{}
+// SetTokenStore sets the store the access token is read from. The token
+// is loaded from the store on every request, so tokens refreshed by other
+// clients or processes are picked up automatically.
+func (c *Client) SetTokenStore(s TokenStore) {
+	c.tokenStore = s
+}
+
+// OnTokenExpired sets a callback which is fired when a request fails with
+// a TokenException, which happens once the session expires at 6 AM IST.
+func (c *Client) OnTokenExpired(f func(err Error)) {
+	c.onTokenExpired = f
+}
+
+// getAccessToken returns the access token from the token store, falling back
+// to the one set on the client. A stored token which has expired isn't sent:
+// the token set on the client is used if it is another one, and otherwise a
+// TokenException is returned. The token expired callback is fired once for
+// each expired token.
+func (c *Client) getAccessToken() (string, error) {
+	c.tokenMu.Lock()
+	accessToken := c.accessToken
+	c.tokenMu.Unlock()
+
+	if c.tokenStore == nil {
+		return accessToken, nil
 	}
 
-	// Send custom headers set
-	if headers == nil {
-		headers = map[string][]string{}
+	t, err := c.tokenStore.Load()
+	if err != nil || t.AccessToken == "" {
+		return accessToken, nil
 	}
 
-	// Add Kite Connect version to header
-	headers.Add("X-Kite-Version", kiteHeaderVersion)
-	headers.Add("User-Agent", name+"/"+version)
+	if !t.Expired(time.Now()) {
+		return t.AccessToken, nil
+	}
 
-	if c.accessToken != "" {
-		authHeader := fmt.Sprintf("enctoken %s", c.accessToken)
-		headers.Add("Authorization", authHeader)
+	expired := newError(TokenError, "Stored access token has expired", http.StatusForbidden, nil)
+
+	c.tokenMu.Lock()
+	report := c.expiredToken != t.AccessToken
+	c.expiredToken = t.AccessToken
+	c.tokenMu.Unlock()
+
+	if report && c.onTokenExpired != nil {
+		c.onTokenExpired(expired)
+	}
+
+	if accessToken == "" || accessToken == t.AccessToken {
+		return "", expired
+	}
+
+	return accessToken, nil
+}
+
+// saveToken saves t to the token store, if one is set.
+func (c *Client) saveToken(t Token) error {
+	if c.tokenStore == nil {
+		return nil
 	}
 
-	if strings.Contains(uri, "oms") {
-		c.baseURI = kiteBaseURI
+	if err := c.tokenStore.Save(t); err != nil {
+		return wrapError(GeneralError, "Error saving access token", err)
 	}
 
-	return c.httpClient.DoEnvelope(method, c.baseURI+uri, params, headers, v)
+	return nil
+}
+
+// checkTokenError fires the token expired callback if err is a TokenException.
+func (c *Client) checkTokenError(err error) error {
+	if e, ok := err.(Error); ok && e.ErrorType == TokenError && c.onTokenExpired != nil {
+		c.onTokenExpired(e)
+	}
+
+	return err
+}
+
+// readEnvelope parses the response of a raw request, see readEnvelope.
+func (c *Client) readEnvelope(resp HTTPResponse, obj interface{}) error {
+	return c.checkTokenError(readEnvelope(resp, obj))
 }
 
-func (c *Client) do(method, uri string, params url.Values, headers http.Header) (HTTPResponse, error) {
+// SetAPIKey sets the Kite Connect app api key used by the login flow and
+// the token auth scheme.
+func (c *Client) SetAPIKey(apiKey string) {
+	c.apiKey = apiKey
+}
+
+// SetAuthScheme sets the Authorization scheme used for Connect API requests.
+// GenerateSession switches the client to AuthSchemeToken.
+func (c *Client) SetAuthScheme(scheme AuthScheme) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.authScheme = scheme
+}
+
+// GetLoginURL gets Kite Connect login endpoint.
+func (c *Client) GetLoginURL() string {
+	return fmt.Sprintf(loginURI, c.apiKey)
+}
+
+func (c *Client) doEnvelope(ctx context.Context, method, uri string, params url.Values, headers http.Header, v interface{}) error {
 	if params == nil {
 		params = url.Values{}
 	}
 
-	if headers == nil {
-		headers = map[string][]string{}
+	ctx, rURL, headers, err := c.prepareRequest(ctx, method, uri, headers)
+	if err != nil {
+		return err
 	}
 
-	headers.Add("X-Kite-Version", kiteHeaderVersion)
-	headers.Add("User-Agent", name+"/"+version)
+	return c.checkTokenError(c.httpClient.DoEnvelope(ctx, method, rURL, params, headers, v))
+}
 
-	if c.accessToken != "" {
-		authHeader := fmt.Sprintf("enctoken %s", c.accessToken)
-		headers.Add("Authorization", authHeader)
+func (c *Client) do(ctx context.Context, method, uri string, params url.Values, headers http.Header) (HTTPResponse, error) {
+	if params == nil {
+		params = url.Values{}
 	}
-	if strings.Contains(uri, "oms") {
-		c.baseURI = kiteBaseURI
+
+	ctx, rURL, headers, err := c.prepareRequest(ctx, method, uri, headers)
+	if err != nil {
+		return HTTPResponse{}, err
+	}
+
+	return c.httpClient.Do(ctx, method, rURL, params, headers)
+}
+
+func (c *Client) doRaw(ctx context.Context, method, uri string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
+	ctx, rURL, headers, err := c.prepareRequest(ctx, method, uri, headers)
+	if err != nil {
+		return HTTPResponse{}, err
 	}
-	return c.httpClient.Do(method, c.baseURI+uri, params, headers)
+
+	return c.httpClient.DoRaw(ctx, method, rURL, reqBody, headers)
 }
 
-func (c *Client) doRaw(method, uri string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
+// prepareRequest resolves the backend of a request and returns its absolute
+// URL along with the headers carrying the Kite version and authorization.
+// The returned context makes the HTTP client wait for the rate limiter
+// before each attempt of the request, retries included. Requests other than
+// logging in fail without being sent if the stored access token has expired.
+func (c *Client) prepareRequest(ctx context.Context, method, uri string, headers http.Header) (context.Context, string, http.Header, error) {
+	// Send custom headers set
 	if headers == nil {
 		headers = map[string][]string{}
 	}
 
+	// Add Kite Connect version to header
 	headers.Add("X-Kite-Version", kiteHeaderVersion)
 	headers.Add("User-Agent", name+"/"+version)
 
-	if c.accessToken != "" {
-		authHeader := fmt.Sprintf("enctoken %s", c.accessToken)
+	b := c.backend(ctx)
+	authHeader, err := c.authHeader(b)
+	if err != nil && uri != URIUserSession && uri != URIUserSessionRenew {
+		return ctx, "", headers, withRequest(err, method, uri)
+	}
+
+	if authHeader != "" {
 		headers.Add("Authorization", authHeader)
 	}
-	if strings.Contains(uri, "oms") {
-		c.baseURI = kiteBaseURI
+
+	if c.limiter != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		l, class := c.limiter, GetEndpointClass(method, uri)
+		ctx = withAttemptWait(ctx, func(ctx context.Context) error {
+			return l.Wait(ctx, class)
+		})
 	}
-	return c.httpClient.DoRaw(method, c.baseURI+uri, reqBody, headers)
+
+	return ctx, c.requestURL(b, uri), headers, nil
 }
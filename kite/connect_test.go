@@ -1,5 +1,122 @@
-package kite
+package kite_test
 
-func getKite() *Client {
-	return New("oy+I7HauRHYvD5wHiUfT9ehPO1md9jhYKo5Pi8LSxrY3fMdifBm32h2mdYnrwCsXRRBZ0i82XBBT0jL8NxG4glqpoWxfbp12/Rx2jmtQJx3luWpLLHnZHQ==")
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/santoshanand/at-kite/kitetest"
+)
+
+// srv is the stand-in API server shared by the endpoint tests.
+var srv *kitetest.Server
+
+func TestMain(m *testing.M) {
+	srv = kitetest.NewServer()
+	srv.SetAccessToken("test_access_token")
+	seedServer(srv)
+
+	code := m.Run()
+	srv.Close()
+	os.Exit(code)
+}
+
+func getKite() *kite.Client {
+	return srv.NewClient()
+}
+
+// seedServer loads the fixtures the endpoint tests assert against.
+func seedServer(s *kitetest.Server) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	ts := kite.Time{Time: time.Date(2023, 7, 14, 9, 30, 0, 0, ist)}
+
+	s.SetProfile(kite.UserProfile{
+		UserID:    "AB1234",
+		UserName:  "Test User",
+		Email:     "test@example.com",
+		Broker:    "ZERODHA",
+		Exchanges: []string{kite.ExchangeNSE, kite.ExchangeNFO},
+	})
+
+	s.SetMargins(kite.AllMargins{
+		Equity:    kite.Margins{Enabled: true, Net: 99725.05},
+		Commodity: kite.Margins{Enabled: true, Net: 100661.7},
+	})
+
+	s.AddOrders(
+		kite.Order{OrderID: "151220000000000", Status: kite.OrderStatusComplete, Variety: kite.VarietyRegular, Exchange: kite.ExchangeNSE, TradingSymbol: "INFY", ExchangeTimestamp: ts, OrderTimestamp: ts, ExchangeUpdateTimestamp: ts},
+		kite.Order{OrderID: "151220000000001", Status: kite.OrderStatusComplete, Variety: kite.VarietyRegular, Tag: "connect test order1", Tags: []string{"connect test order1"}},
+		kite.Order{OrderID: "151220000000002", Status: kite.OrderStatusComplete, Variety: kite.VarietyRegular, Tag: "connect test order2", Tags: []string{"connect test order2", "XXXXX"}},
+		kite.Order{OrderID: "151220000000003", Status: "OPEN", Variety: kite.VarietyIceberg, Validity: kite.ValidityTTL, Meta: map[string]interface{}{
+			"iceberg": map[string]interface{}{"leg": 1, "legs": 5, "leg_quantity": 200, "total_quantity": 1000, "remaining_quantity": 800},
+		}},
+		kite.Order{OrderID: "test", Status: "OPEN", Variety: kite.VarietyRegular, Quantity: 100, PendingQuantity: 100},
+		kite.Order{OrderID: "test-cancel", Status: "OPEN", Variety: kite.VarietyRegular, Quantity: 100, PendingQuantity: 100},
+		kite.Order{OrderID: "test-exit", Status: "OPEN", Variety: kite.VarietyCO, Quantity: 100, PendingQuantity: 100},
+	)
+
+	s.AddTrades(
+		kite.Trade{TradeID: "10000000", OrderID: "151220000000000", Quantity: 1, Exchange: kite.ExchangeNSE, TradingSymbol: "INFY", FillTimestamp: ts},
+		kite.Trade{TradeID: "10000001", OrderID: "test", Quantity: 50, FillTimestamp: ts},
+	)
+
+	s.SetPositions(kite.Positions{
+		Net: []kite.Position{{Tradingsymbol: "LEADMINI17DECFUT", Exchange: kite.ExchangeMCX, Quantity: 1}},
+		Day: []kite.Position{{Tradingsymbol: "GOLDGUINEA17DECFUT", Exchange: kite.ExchangeMCX, Quantity: -3}},
+	})
+
+	s.SetHoldings(kite.Holdings{
+		{Tradingsymbol: "INFY", Exchange: kite.ExchangeNSE, InstrumentToken: 408065, Quantity: 1},
+	})
+
+	s.SetQuotes(kite.Quote{
+		"NSE:INFY": {InstrumentToken: 408065, LastPrice: 1074.35, OHLC: kite.OHLC{Open: 1075, High: 1085, Low: 1053.5, Close: 1075.6}},
+	})
+
+	var candles []kite.HistoricalData
+	for i := 0; i < 6; i++ {
+		candles = append(candles, kite.HistoricalData{
+			Date:   kite.Time{Time: time.Date(2023, 7, 14, 9, 15+i, 0, 0, ist)},
+			Open:   100 + float64(i),
+			High:   101 + float64(i),
+			Low:    99 + float64(i),
+			Close:  100.5 + float64(i),
+			Volume: 1000 * (i + 1),
+			OI:     5000 + i,
+		})
+	}
+	s.SetCandles(123, "myinterval", candles)
+	s.SetCandles(456, "myinterval", candles)
+
+	s.SetInstruments(kite.Instruments{
+		{InstrumentToken: 408065, ExchangeToken: 1594, Tradingsymbol: "INFY", Name: "INFOSYS", TickSize: 0.05, LotSize: 1, InstrumentType: "EQ", Segment: kite.ExchangeNSE, Exchange: kite.ExchangeNSE},
+		{InstrumentToken: 12074242, ExchangeToken: 47165, Tradingsymbol: "NIFTY18JUN10500CE", Name: "NIFTY", Expiry: kite.Time{Time: time.Date(2018, 6, 28, 0, 0, 0, 0, ist)}, StrikePrice: 10500, TickSize: 0.05, LotSize: 75, InstrumentType: "CE", Segment: "NFO-OPT", Exchange: kite.ExchangeNFO},
+	})
+
+	s.SetMFInstruments(kite.MFInstruments{
+		{Tradingsymbol: "INF209K01157", Name: "Aditya Birla Sun Life Advantage Fund", AMC: "BirlaSunLifeMutualFund_MF", PurchaseAllowed: true, RedemtpionAllowed: true, LastPrice: 106.8},
+	})
+
+	s.AddGTTs(
+		kite.GTT{ID: 123, Type: kite.GTTTypeSingle, Status: "active", Condition: kite.GTTCondition{Exchange: kite.ExchangeNSE, Tradingsymbol: "INFY", TriggerValues: []float64{702}}},
+		kite.GTT{ID: 132359442, Type: kite.GTTTypeSingle, Status: "active", Condition: kite.GTTCondition{Exchange: kite.ExchangeNSE, Tradingsymbol: "INFY", TriggerValues: []float64{800}}},
+	)
+
+	s.SetOrderMargins([]kite.OrderMargins{
+		{Type: "equity", TradingSymbol: "INFY", Exchange: kite.ExchangeNSE, Total: 961.45},
+	})
+
+	s.SetBasketMargins(kite.BasketMargins{
+		Initial: kite.OrderMargins{Total: 1922.9},
+		Final:   kite.OrderMargins{Total: 1922.9},
+		Orders: []kite.OrderMargins{
+			{TradingSymbol: "INFY", Exchange: kite.ExchangeNSE, Total: 961.45},
+			{TradingSymbol: "INFY", Exchange: kite.ExchangeNSE, Total: 961.45},
+		},
+	})
+
+	s.AddMFOrders(kite.MFOrder{OrderID: "test", Tradingsymbol: "INF174K01LS2", Status: "COMPLETE", TransactionType: kite.TransactionTypeBuy, Amount: 5000})
+	s.AddMFSIPs(kite.MFSIP{ID: "test", Tradingsymbol: "INF174K01LS2", Status: "ACTIVE", Frequency: "monthly", InstalmentAmount: 1000})
+	s.SetMFHoldings(kite.MFHoldings{{Folio: "123123/123", Tradingsymbol: "INF084M01AB8", Quantity: 2.6}})
 }
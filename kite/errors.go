@@ -4,7 +4,11 @@
 
 package kite
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
 
 // API errors. Check documantation to learn about individual exception: https://kite.trade/docs/connect/v3/exceptions/.
 const (
@@ -17,21 +21,116 @@ const (
 	InputError      = "InputException"
 	DataError       = "DataException"
 	NetworkError    = "NetworkException"
+	// ContextError is returned when a request is aborted because its
+	// context was cancelled or its deadline was exceeded.
+	ContextError = "ContextException"
+)
+
+// Sentinel errors to check an Error against with errors.Is.
+//
+//	if errors.Is(err, kite.ErrTokenExpired) {
+//		// Log in again.
+//	}
+var (
+	ErrGeneral       = errors.New("kite: general error")
+	ErrTokenExpired  = errors.New("kite: session expired or invalid")
+	ErrPermission    = errors.New("kite: permission denied")
+	ErrUser          = errors.New("kite: user account error")
+	ErrTwoFA         = errors.New("kite: two factor authentication failed")
+	ErrOrderRejected = errors.New("kite: order rejected")
+	ErrInput         = errors.New("kite: invalid input")
+	ErrData          = errors.New("kite: invalid response")
+	ErrNetwork       = errors.New("kite: network error")
+	ErrRateLimited   = errors.New("kite: rate limited")
+	ErrAborted       = errors.New("kite: request aborted")
 )
 
+// sentinelTypes maps sentinel errors to the error types they match.
+var sentinelTypes = map[error]string{
+	ErrGeneral:       GeneralError,
+	ErrTokenExpired:  TokenError,
+	ErrPermission:    PermissionError,
+	ErrUser:          UserError,
+	ErrTwoFA:         TwoFAError,
+	ErrOrderRejected: OrderError,
+	ErrInput:         InputError,
+	ErrData:          DataError,
+	ErrNetwork:       NetworkError,
+	ErrAborted:       ContextError,
+}
+
 // Error is the error type used for all API errors.
 type Error struct {
 	Code      int
 	ErrorType string
 	Message   string
 	Data      interface{}
+
+	// Method and Path are the HTTP method and URL path of the request that
+	// failed, if the error is tied to one.
+	Method string
+	Path   string
+	// Status is the HTTP status code of the response. It is zero if no
+	// response was received, unlike Code which is then derived from the
+	// error type.
+	Status int
+	// Err is the underlying error, such as a net or JSON error, if any.
+	Err error
 }
 
 // This makes Error a valid Go error type.
 func (e Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
 	return e.Message
 }
 
+// Unwrap returns the underlying error.
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches one of the sentinel errors, such as
+// ErrTokenExpired for a TokenException. ErrRateLimited matches errors with
+// the 429 Too Many Requests code, whether sent by the API or by the client
+// side rate limiter.
+func (e Error) Is(target error) bool {
+	if target == ErrRateLimited {
+		return e.Code == http.StatusTooManyRequests
+	}
+
+	etype, ok := sentinelTypes[target]
+	return ok && e.ErrorType == etype
+}
+
+// IsRetryable reports whether the request may succeed if it's sent again:
+// the request was rate limited, the API was temporarily unavailable or the
+// connection failed with a transient error like a reset or a timeout.
+// Errors returned by the API for the request itself, such as an invalid
+// input or a rejected order, and aborted requests are not retryable.
+func (e Error) IsRetryable() bool {
+	if e.ErrorType == ContextError {
+		return false
+	}
+
+	if e.Err != nil && isTransientNetError(e.Err) {
+		return true
+	}
+
+	switch e.Code {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// Network errors without a response, like a refused connection,
+		// are mapped to 503 as well.
+		return e.Status != 0
+	}
+
+	return false
+}
+
 // NewError creates and returns a new instace of Error
 // with custom error metadata.
 func NewError(etype string, message string, data interface{}) error {
@@ -58,6 +157,8 @@ func NewError(etype string, message string, data interface{}) error {
 		code = http.StatusGatewayTimeout
 	case NetworkError:
 		code = http.StatusServiceUnavailable
+	case ContextError:
+		code = http.StatusRequestTimeout
 	default:
 		code = http.StatusInternalServerError
 		etype = GeneralError
@@ -66,6 +167,52 @@ func NewError(etype string, message string, data interface{}) error {
 	return newError(etype, message, code, data)
 }
 
+// wrapError returns an Error of type etype caused by err.
+func wrapError(etype, message string, err error) error {
+	e := NewError(etype, message, nil).(Error)
+	e.Err = err
+	return e
+}
+
+// withRequest attaches the method and path of the request that failed to
+// err, if it is an Error which doesn't carry them yet. The query string is
+// left out as it may carry credentials.
+func withRequest(err error, method, rURL string) error {
+	e, ok := err.(Error)
+	if !ok || e.Method != "" {
+		return err
+	}
+
+	e.Method = method
+	e.Path = rURL
+	if u, perr := url.Parse(rURL); perr == nil {
+		e.Path = u.Path
+	}
+
+	return e
+}
+
+// redactURLError returns a copy of err with the credentials in its URL
+// masked, if it is a *url.Error such as those returned by http.Client.
+func redactURLError(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+
+	u, perr := url.Parse(ue.URL)
+	if perr != nil {
+		return &url.Error{Op: ue.Op, URL: redacted, Err: ue.Err}
+	}
+
+	prefix := ""
+	if u.Host != "" {
+		prefix = u.Scheme + "://" + u.Host
+	}
+
+	return &url.Error{Op: ue.Op, URL: prefix + RedactURL(u), Err: ue.Err}
+}
+
 func newError(etype, message string, code int, data interface{}) Error {
 	return Error{
 		Message:   message,
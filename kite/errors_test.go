@@ -1,8 +1,18 @@
 package kite
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetErrorName(t *testing.T) {
@@ -165,6 +175,13 @@ func TestNewError(t *testing.T) {
 			},
 			want: http.StatusServiceUnavailable,
 		},
+		{
+			name: "Context Error",
+			args: args{
+				etype: ContextError,
+			},
+			want: http.StatusRequestTimeout,
+		},
 		{
 			name: "Default Error",
 			args: args{
@@ -184,3 +201,121 @@ func TestNewError(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorIs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err    error
+		target error
+		want   bool
+	}{
+		{NewError(TokenError, "expired", nil), ErrTokenExpired, true},
+		{NewError(OrderError, "rejected", nil), ErrOrderRejected, true},
+		{NewError(OrderError, "rejected", nil), ErrTokenExpired, false},
+		{NewError(InputError, "bad", nil), ErrInput, true},
+		{NewError(ContextError, "aborted", nil), ErrAborted, true},
+		{newError(NetworkError, "Too many requests", http.StatusTooManyRequests, nil), ErrRateLimited, true},
+		{newError(NetworkError, "Too many requests", http.StatusTooManyRequests, nil), ErrNetwork, true},
+		{NewError(NetworkError, "failed", nil), ErrRateLimited, false},
+		{fmt.Errorf("placing order: %w", NewError(OrderError, "rejected", nil)), ErrOrderRejected, true},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, errors.Is(tt.err, tt.target), "%v is %v", tt.err, tt.target)
+	}
+}
+
+func TestErrorIsRetryable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  Error
+		want bool
+	}{
+		{"rate limited", newError(NetworkError, "", http.StatusTooManyRequests, nil), true},
+		{"unavailable", Error{ErrorType: NetworkError, Code: http.StatusServiceUnavailable, Status: http.StatusServiceUnavailable}, true},
+		{"gateway timeout", Error{ErrorType: DataError, Code: http.StatusGatewayTimeout, Status: http.StatusGatewayTimeout}, true},
+		{"connection reset", wrapError(NetworkError, "", syscall.ECONNRESET).(Error), true},
+		{"connection refused", wrapError(NetworkError, "", syscall.ECONNREFUSED).(Error), false},
+		{"input", newError(InputError, "", http.StatusBadRequest, nil), false},
+		{"order", newError(OrderError, "", http.StatusBadRequest, nil), false},
+		{"token", newError(TokenError, "", http.StatusForbidden, nil), false},
+		{"aborted", wrapError(ContextError, "", context.DeadlineExceeded).(Error), false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.err.IsRetryable(), tt.name)
+	}
+}
+
+func TestErrorCause(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIGetOrders:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","error_type":"OrderException","message":"Insufficient funds."}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":`))
+		}
+	}))
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRateLimiter(nil)
+	kc.SetRetryPolicy(NoRetryPolicy())
+
+	_, err := kc.GetOrders()
+	require.True(t, errors.Is(err, ErrOrderRejected))
+	var e Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, http.MethodGet, e.Method)
+	require.Equal(t, URIGetOrders, e.Path)
+	require.Equal(t, http.StatusBadRequest, e.Status)
+	require.Equal(t, "Insufficient funds.", e.Error())
+
+	// Malformed JSON keeps the decoding error.
+	_, err = kc.GetTrades()
+	require.True(t, errors.Is(err, ErrData))
+	var syntaxErr *json.SyntaxError
+	require.True(t, errors.As(err, &syntaxErr))
+	require.Equal(t, URIGetTrades, err.(Error).Path)
+	require.Equal(t, http.StatusOK, err.(Error).Status)
+
+	// Transport errors keep the net error.
+	ts.Close()
+	_, err = kc.GetPositions()
+	require.True(t, errors.Is(err, ErrNetwork))
+	var opErr *net.OpError
+	require.True(t, errors.As(err, &opErr))
+	require.Equal(t, URIGetPositions, err.(Error).Path)
+	require.Equal(t, 0, err.(Error).Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = kc.GetHoldingsWithContext(ctx)
+	require.True(t, errors.Is(err, ErrAborted))
+	require.True(t, errors.Is(err, context.Canceled))
+	require.False(t, err.(Error).IsRetryable())
+}
+
+func TestNetworkErrorRedactsCredentials(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	kc := New("k")
+	kc.SetBaseURI(ts.URL)
+	kc.SetAccessToken("SECRETTOKEN")
+	kc.SetRetryPolicy(NoRetryPolicy())
+
+	_, err := kc.InvalidateAccessToken()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrNetwork))
+	require.NotContains(t, err.Error(), "SECRETTOKEN")
+	require.Contains(t, err.Error(), "access_token=[REDACTED]")
+
+	var ue *url.Error
+	require.True(t, errors.As(err, &ue))
+	require.NotNil(t, ue.Err)
+}
@@ -1,6 +1,7 @@
 package kite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -158,6 +159,11 @@ type GTTResponse struct {
 
 // PlaceGTT constructs and places a GTT order using GTTParams.
 func (c *Client) PlaceGTT(o GTTParams) (GTTResponse, error) {
+	return c.PlaceGTTWithContext(context.Background(), o)
+}
+
+// PlaceGTTWithContext is the context aware variant of PlaceGTT.
+func (c *Client) PlaceGTTWithContext(ctx context.Context, o GTTParams) (GTTResponse, error) {
 	var (
 		params    = url.Values{}
 		gtt       = newGTT(o)
@@ -178,12 +184,17 @@ func (c *Client) PlaceGTT(o GTTParams) (GTTResponse, error) {
 	params.Add("condition", string(condition))
 	params.Add("orders", string(orders))
 
-	err = c.doEnvelope(http.MethodPost, URIPlaceGTT, params, nil, &orderResp)
+	err = c.doEnvelope(ctx, http.MethodPost, URIPlaceGTT, params, nil, &orderResp)
 	return orderResp, err
 }
 
 // ModifyGTT modifies the condition or orders inside an already created GTT order.
 func (c *Client) ModifyGTT(triggerID int, o GTTParams) (GTTResponse, error) {
+	return c.ModifyGTTWithContext(context.Background(), triggerID, o)
+}
+
+// ModifyGTTWithContext is the context aware variant of ModifyGTT.
+func (c *Client) ModifyGTTWithContext(ctx context.Context, triggerID int, o GTTParams) (GTTResponse, error) {
 	var (
 		params    = url.Values{}
 		gtt       = newGTT(o)
@@ -204,27 +215,42 @@ func (c *Client) ModifyGTT(triggerID int, o GTTParams) (GTTResponse, error) {
 	params.Add("condition", string(condition))
 	params.Add("orders", string(orders))
 
-	err = c.doEnvelope(http.MethodPut, fmt.Sprintf(URIModifyGTT, triggerID), params, nil, &orderResp)
+	err = c.doEnvelope(ctx, http.MethodPut, fmt.Sprintf(URIModifyGTT, triggerID), params, nil, &orderResp)
 	return orderResp, err
 }
 
 // GetGTTs returns the current GTTs for the user.
 func (c *Client) GetGTTs() (GTTs, error) {
+	return c.GetGTTsWithContext(context.Background())
+}
+
+// GetGTTsWithContext is the context aware variant of GetGTTs.
+func (c *Client) GetGTTsWithContext(ctx context.Context) (GTTs, error) {
 	var orders GTTs
-	err := c.doEnvelope(http.MethodGet, URIGetGTTs, nil, nil, &orders)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetGTTs, nil, nil, &orders)
 	return orders, err
 }
 
 // GetGTT returns a specific GTT for the user.
 func (c *Client) GetGTT(triggerID int) (GTT, error) {
+	return c.GetGTTWithContext(context.Background(), triggerID)
+}
+
+// GetGTTWithContext is the context aware variant of GetGTT.
+func (c *Client) GetGTTWithContext(ctx context.Context, triggerID int) (GTT, error) {
 	var order GTT
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetGTT, triggerID), nil, nil, &order)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetGTT, triggerID), nil, nil, &order)
 	return order, err
 }
 
 // DeleteGTT deletes a GTT order.
 func (c *Client) DeleteGTT(triggerID int) (GTTResponse, error) {
+	return c.DeleteGTTWithContext(context.Background(), triggerID)
+}
+
+// DeleteGTTWithContext is the context aware variant of DeleteGTT.
+func (c *Client) DeleteGTTWithContext(ctx context.Context, triggerID int) (GTTResponse, error) {
 	var order GTTResponse
-	err := c.doEnvelope(http.MethodDelete, fmt.Sprintf(URIGetGTT, triggerID), nil, nil, &order)
+	err := c.doEnvelope(ctx, http.MethodDelete, fmt.Sprintf(URIGetGTT, triggerID), nil, nil, &order)
 	return order, err
 }
@@ -1,8 +1,9 @@
-package kite
+package kite_test
 
 import (
 	"testing"
 
+	"github.com/santoshanand/at-kite/kite"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,13 +33,13 @@ func TestGetGTT(t *testing.T) {
 
 func TestModifyGTT(t *testing.T) {
 	t.Parallel()
-	gttOrder, err := getKite().ModifyGTT(123, GTTParams{
+	gttOrder, err := getKite().ModifyGTT(123, kite.GTTParams{
 		Tradingsymbol:   "INFY",
 		Exchange:        "NSE",
 		LastPrice:       800,
-		TransactionType: TransactionTypeBuy,
-		Trigger: &GTTSingleLegTrigger{
-			TriggerParams: TriggerParams{
+		TransactionType: kite.TransactionTypeBuy,
+		Trigger: &kite.GTTSingleLegTrigger{
+			TriggerParams: kite.TriggerParams{
 				TriggerValue: 2,
 				Quantity:     2,
 				LimitPrice:   2,
@@ -55,13 +56,13 @@ func TestModifyGTT(t *testing.T) {
 
 func TestPlaceGTT(t *testing.T) {
 	t.Parallel()
-	gttOrder, err := getKite().PlaceGTT(GTTParams{
+	gttOrder, err := getKite().PlaceGTT(kite.GTTParams{
 		Tradingsymbol:   "INFY",
 		Exchange:        "NSE",
 		LastPrice:       800,
-		TransactionType: TransactionTypeBuy,
-		Trigger: &GTTSingleLegTrigger{
-			TriggerParams: TriggerParams{
+		TransactionType: kite.TransactionTypeBuy,
+		Trigger: &kite.GTTSingleLegTrigger{
+			TriggerParams: kite.TriggerParams{
 				TriggerValue: 1,
 				Quantity:     1,
 				LimitPrice:   1,
@@ -0,0 +1,160 @@
+package kite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// historicalMaxDays is the longest range, in days, a single historical data
+// request accepts for each interval.
+var historicalMaxDays = map[string]int{
+	IntervalMinute:   60,
+	Interval3Minute:  100,
+	Interval5Minute:  100,
+	Interval10Minute: 100,
+	Interval15Minute: 200,
+	Interval30Minute: 200,
+	Interval60Minute: 400,
+	IntervalDay:      2000,
+}
+
+// MaxHistoricalDays returns the longest range, in days, a single historical
+// data request accepts for interval, or 0 for unknown intervals.
+func MaxHistoricalDays(interval string) int {
+	return historicalMaxDays[interval]
+}
+
+// maxHistoricalConcurrency is the number of windows fetched at once by
+// GetHistoricalDataRange. Requests are paced by the rate limiter in any case.
+const maxHistoricalConcurrency = 3
+
+// historicalWindow is a range fetched with a single request.
+type historicalWindow struct {
+	from, to time.Time
+}
+
+// historicalWindows splits [from, to] into windows of at most days. Windows
+// share their boundaries, so no candle falls between two of them.
+func historicalWindows(from, to time.Time, days int) []historicalWindow {
+	var windows []historicalWindow
+	for {
+		end := from.AddDate(0, 0, days)
+		if !end.Before(to) {
+			return append(windows, historicalWindow{from, to})
+		}
+
+		windows = append(windows, historicalWindow{from, end})
+		from = end
+	}
+}
+
+// GetHistoricalDataRange fetches the candles of an instrument between
+// fromDate and toDate like GetHistoricalData, but splits ranges longer than
+// a request of the interval accepts into windows which are fetched
+// concurrently. The candles are returned in order, without the duplicates
+// at the boundaries of the windows.
+//
+//	candles, err := kc.GetHistoricalDataRange(256265, kite.IntervalMinute, from, to, false, false)
+func (c *Client) GetHistoricalDataRange(instrumentToken int, interval string, fromDate time.Time, toDate time.Time, continuous bool, OI bool) ([]HistoricalData, error) {
+	return c.GetHistoricalDataRangeWithContext(context.Background(), instrumentToken, interval, fromDate, toDate, continuous, OI)
+}
+
+// GetHistoricalDataRangeWithContext is the context aware variant of GetHistoricalDataRange.
+func (c *Client) GetHistoricalDataRangeWithContext(ctx context.Context, instrumentToken int, interval string, fromDate time.Time, toDate time.Time, continuous bool, OI bool) ([]HistoricalData, error) {
+	days, ok := historicalMaxDays[interval]
+	if !ok {
+		return nil, NewError(InputError, fmt.Sprintf("Unknown candle interval: %s", interval), nil)
+	}
+
+	if toDate.Before(fromDate) {
+		return nil, NewError(InputError, "Invalid range: to date is before from date", nil)
+	}
+
+	windows := historicalWindows(fromDate, toDate, days)
+	if len(windows) == 1 {
+		return c.GetHistoricalDataWithContext(ctx, instrumentToken, interval, fromDate, toDate, continuous, OI)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, maxHistoricalConcurrency)
+		results = make([][]HistoricalData, len(windows))
+		errs    = make([]error, len(windows))
+	)
+
+	for i, w := range windows {
+		wg.Add(1)
+		go func(i int, w historicalWindow) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = wrapError(ContextError, "Request aborted", ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = c.GetHistoricalDataWithContext(ctx, instrumentToken, interval, w.from, w.to, continuous, OI)
+			if errs[i] != nil {
+				// The other windows are of no use without this one.
+				cancel()
+			}
+		}(i, w)
+	}
+	wg.Wait()
+
+	// Report the failure which caused the others, rather than the
+	// cancellations it triggered.
+	var first error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if first == nil {
+			first = err
+		}
+
+		if e, ok := err.(Error); !ok || e.ErrorType != ContextError {
+			return nil, err
+		}
+	}
+
+	if first != nil {
+		return nil, first
+	}
+
+	return mergeCandles(results), nil
+}
+
+// mergeCandles merges candles of overlapping windows into a single ordered
+// list without duplicates.
+func mergeCandles(windows [][]HistoricalData) []HistoricalData {
+	var out []HistoricalData
+	for _, w := range windows {
+		out = append(out, w...)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Date.Before(out[j].Date.Time)
+	})
+
+	n := 0
+	for i, d := range out {
+		if i > 0 && d.Date.Equal(out[n-1].Date.Time) {
+			continue
+		}
+
+		out[n] = d
+		n++
+	}
+
+	return out[:n]
+}
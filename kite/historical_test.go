@@ -0,0 +1,70 @@
+package kite_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/santoshanand/at-kite/kitetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHistoricalDataRange(t *testing.T) {
+	t.Parallel()
+	s := kitetest.NewServer()
+	defer s.Close()
+
+	// One candle a day at the open, for 150 days.
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	from := time.Date(2023, 1, 2, 9, 15, 0, 0, ist)
+	var candles []kite.HistoricalData
+	for i := 0; i < 150; i++ {
+		candles = append(candles, kite.HistoricalData{
+			Date:  kite.Time{Time: from.AddDate(0, 0, i)},
+			Close: float64(i),
+		})
+	}
+	s.SetCandles(256265, kite.IntervalMinute, candles)
+	kc := s.NewClient()
+	kc.SetRetryPolicy(kite.NoRetryPolicy())
+
+	to := from.AddDate(0, 0, 149)
+	data, err := kc.GetHistoricalDataRange(256265, kite.IntervalMinute, from, to, false, false)
+	require.Nil(t, err)
+	require.Equal(t, 150, len(data))
+	for i, d := range data {
+		require.True(t, candles[i].Date.Equal(d.Date.Time))
+		require.Equal(t, float64(i), d.Close)
+	}
+
+	// 150 days of minute candles take three windows of 60 days, which share
+	// the candles at their boundaries.
+	require.Equal(t, 3, len(s.Requests()))
+
+	// Ranges within the limit take a single request.
+	data, err = kc.GetHistoricalDataRange(256265, kite.IntervalDay, from, to, false, false)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(data))
+	require.Equal(t, 4, len(s.Requests()))
+
+	_, err = kc.GetHistoricalDataRange(256265, "2minute", from, to, false, false)
+	require.Equal(t, kite.InputError, err.(kite.Error).ErrorType)
+	_, err = kc.GetHistoricalDataRange(256265, kite.IntervalMinute, to, from, false, false)
+	require.Equal(t, kite.InputError, err.(kite.Error).ErrorType)
+
+	// A failed window fails the range.
+	s.Inject(kitetest.Fault{Path: "/instruments/historical/", Times: 1, Status: http.StatusBadRequest, ErrorType: kite.InputError, Message: "Invalid range"})
+	_, err = kc.GetHistoricalDataRange(256265, kite.IntervalMinute, from, to, false, false)
+	require.Error(t, err)
+	require.Equal(t, kite.InputError, err.(kite.Error).ErrorType)
+	require.Equal(t, "Invalid range", err.(kite.Error).Message)
+
+	// A cancelled context aborts the windows.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = kc.GetHistoricalDataRangeWithContext(ctx, 256265, kite.IntervalMinute, from, to, false, false)
+	require.True(t, errors.Is(err, context.Canceled))
+}
@@ -6,6 +6,7 @@ package kite
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -18,18 +19,21 @@ import (
 
 // HTTPClient represents an HTTP client.
 type HTTPClient interface {
-	Do(method, rURL string, params url.Values, headers http.Header) (HTTPResponse, error)
-	DoRaw(method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error)
-	DoEnvelope(method, url string, params url.Values, headers http.Header, obj interface{}) error
-	DoJSON(method, url string, params url.Values, headers http.Header, obj interface{}) (HTTPResponse, error)
+	Do(ctx context.Context, method, rURL string, params url.Values, headers http.Header) (HTTPResponse, error)
+	DoRaw(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error)
+	DoEnvelope(ctx context.Context, method, url string, params url.Values, headers http.Header, obj interface{}) error
+	DoJSON(ctx context.Context, method, url string, params url.Values, headers http.Header, obj interface{}) (HTTPResponse, error)
+	DoStream(ctx context.Context, method, url string, params url.Values, headers http.Header, fn func(io.Reader) error) error
 	GetClient() *httpClient
 }
 
 // httpClient is the default implementation of HTTPClient.
 type httpClient struct {
-	client *http.Client
-	hLog   *log.Logger
-	debug  bool
+	client      *http.Client
+	hLog        *log.Logger
+	debug       bool
+	retry       RetryPolicy
+	middlewares []Middleware
 }
 
 // HTTPResponse encompasses byte body  + the response of an HTTP request.
@@ -50,7 +54,8 @@ type envelope struct {
 }
 
 // NewHTTPClient returns a self-contained HTTP request object
-// with underlying keep-alive transport.
+// with underlying keep-alive transport. hLog receives errors and, when
+// debug is set, a log line for every request with credentials redacted.
 func NewHTTPClient(h *http.Client, hLog *log.Logger, debug bool) HTTPClient {
 	if hLog == nil {
 		hLog = log.New(os.Stdout, "base.HTTP: ", log.Ldate|log.Ltime|log.Lshortfile)
@@ -70,19 +75,113 @@ func NewHTTPClient(h *http.Client, hLog *log.Logger, debug bool) HTTPClient {
 		hLog:   hLog,
 		client: h,
 		debug:  debug,
+		retry:  DefaultRetryPolicy(),
 	}
 }
 
-func (h *httpClient) Do(method, rURL string, params url.Values, headers http.Header) (HTTPResponse, error) {
+// Do encodes params and executes an HTTP request with DoRaw.
+func (h *httpClient) Do(ctx context.Context, method, rURL string, params url.Values, headers http.Header) (HTTPResponse, error) {
 	if params == nil {
 		params = url.Values{}
 	}
 
-	return h.DoRaw(method, rURL, []byte(params.Encode()), headers)
+	return h.DoRaw(ctx, method, rURL, []byte(params.Encode()), headers)
 }
 
-// Do executes an HTTP request and returns the response.
-func (h *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
+// DoRaw executes an HTTP request and returns the response. The request is
+// bound to ctx, so cancelling ctx aborts an in-flight request with a
+// ContextError. Failed attempts are retried as configured by the client's
+// RetryPolicy.
+func (h *httpClient) DoRaw(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
+	return h.doRetry(ctx, method, rURL, reqBody, headers, nil)
+}
+
+// DoStream executes an HTTP request like Do, but instead of buffering a
+// successful response it passes the body to fn as it arrives. The request
+// is only retried until fn is called. Error responses are parsed from their
+// JSON envelope.
+func (h *httpClient) DoStream(ctx context.Context, method, rURL string, params url.Values, headers http.Header, fn func(io.Reader) error) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	resp, err := h.doRetry(ctx, method, rURL, []byte(params.Encode()), headers, fn)
+	if err != nil {
+		return err
+	}
+
+	if resp.Response.StatusCode >= http.StatusBadRequest {
+		return readEnvelope(resp, nil)
+	}
+
+	return nil
+}
+
+// doRetry makes a request with doAttempt, retrying failed attempts as
+// configured by the client's RetryPolicy.
+func (h *httpClient) doRetry(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header, stream func(io.Reader) error) (HTTPResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	wait, _ := ctx.Value(attemptWaitKey{}).(func(context.Context) error)
+	for attempt := 1; ; attempt++ {
+		if wait != nil {
+			if err := wait(ctx); err != nil {
+				return HTTPResponse{}, withRequest(err, method, rURL)
+			}
+		}
+
+		resp, cause, err := h.doAttempt(ctx, method, rURL, reqBody, headers, stream)
+		err = withRequest(err, method, rURL)
+		if attempt >= h.retry.maxAttempts() || !h.retry.shouldRetry(resp, cause) {
+			return resp, err
+		}
+
+		// Requests that are not idempotent, such as placing an order, are only
+		// retried once the dedup strategy confirms the earlier attempt had no effect.
+		if !isIdempotent(method) {
+			if h.retry.Dedup == nil {
+				return resp, err
+			}
+
+			applied, derr := h.retry.Dedup(ctx, method, rURL, reqBody)
+			if derr != nil || applied {
+				return resp, err
+			}
+		}
+
+		delay := h.retry.backoff(attempt, resp)
+		if h.debug {
+			h.hLog.Printf("Retrying %s %s in %v (attempt %d of %d)", method, redactRawURL(rURL), delay, attempt+1, h.retry.maxAttempts())
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, withRequest(wrapError(ContextError, "Request aborted", ctx.Err()), method, rURL)
+		case <-timer.C:
+		}
+	}
+}
+
+// attemptWaitKey is the context key of the function doRetry calls before
+// each attempt of a request, such as waiting for the rate limiter.
+type attemptWaitKey struct{}
+
+// withAttemptWait returns a copy of ctx which makes doRetry call wait before
+// each attempt and give up with its error.
+func withAttemptWait(ctx context.Context, wait func(context.Context) error) context.Context {
+	return context.WithValue(ctx, attemptWaitKey{}, wait)
+}
+
+// doAttempt makes a single HTTP request. Along with the response and the
+// error to be returned to the caller, it returns the underlying transport
+// error (if any) which is used to decide whether the request can be retried.
+// If stream is set, the body of a successful response is passed to it
+// instead of being read into the response.
+func (h *httpClient) doAttempt(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header, stream func(io.Reader) error) (HTTPResponse, error, error) {
 	var (
 		resp     = HTTPResponse{}
 		err      error
@@ -94,10 +193,11 @@ func (h *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 		postBody = bytes.NewReader(reqBody)
 	}
 
-	req, err := http.NewRequest(method, rURL, postBody)
+	req, err := http.NewRequestWithContext(ctx, method, rURL, postBody)
 	if err != nil {
+		err = redactURLError(err)
 		h.hLog.Printf("Request preparation failed: %v", err)
-		return resp, NewError(NetworkError, "Request preparation failed.", nil)
+		return resp, nil, wrapError(NetworkError, "Request preparation failed", err)
 	}
 
 	if headers != nil {
@@ -116,32 +216,106 @@ func (h *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 		req.URL.RawQuery = string(reqBody)
 	}
 
+	mws := h.chain()
+	for _, m := range mws {
+		if m.BeforeRequest == nil {
+			continue
+		}
+
+		if err := m.BeforeRequest(req); err != nil {
+			return resp, nil, wrapError(InputError, "Request rejected by middleware", err)
+		}
+	}
+
+	start := time.Now()
 	r, err := h.client.Do(req)
 	if err != nil {
+		// The error carries the URL, whose query may hold credentials.
+		err = redactURLError(err)
+		elapsed := time.Since(start)
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i].OnError != nil {
+				mws[i].OnError(req, err, elapsed)
+			}
+		}
+
+		// Cancellation and deadlines are reported separately so that callers
+		// can tell a shutdown apart from a network failure.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return resp, nil, wrapError(ContextError, "Request aborted", ctxErr)
+		}
+
 		h.hLog.Printf("Request failed: %v", err)
-		return resp, NewError(NetworkError, "Request failed.", nil)
+		return resp, err, wrapError(NetworkError, "Request failed", err)
 	}
 
 	defer r.Body.Close()
 
+	if stream != nil && r.StatusCode < http.StatusBadRequest {
+		resp.Response = r
+		err := stream(r.Body)
+		elapsed := time.Since(start)
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i].AfterResponse != nil {
+				mws[i].AfterResponse(req, r, nil, elapsed)
+			}
+		}
+
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return resp, nil, wrapError(ContextError, "Request aborted", ctxErr)
+			}
+		}
+
+		// Rows may have been handed to the caller already, so a failure
+		// while streaming is never retried.
+		return resp, nil, err
+	}
+
 	body, err := ioutil.ReadAll(r.Body)
+	elapsed := time.Since(start)
 	if err != nil {
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i].OnError != nil {
+				mws[i].OnError(req, err, elapsed)
+			}
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return resp, nil, wrapError(ContextError, "Request aborted", ctxErr)
+		}
+
 		h.hLog.Printf("Unable to read response: %v", err)
-		return resp, NewError(DataError, "Error reading response.", nil)
+		return resp, err, wrapError(DataError, "Error reading response", err)
 	}
 
 	resp.Response = r
 	resp.Body = body
-	// if h.debug {
-	// 	h.hLog.Printf("%s %s -- %d %v", method, req.URL.RequestURI(), resp.Response.StatusCode, req.Header)
-	// }
 
-	return resp, nil
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i].AfterResponse != nil {
+			mws[i].AfterResponse(req, r, body, elapsed)
+		}
+	}
+
+	return resp, nil, nil
+}
+
+// chain returns the middlewares to run for a request. The debug logger
+// runs last, so it sees the headers set by the other middlewares.
+func (h *httpClient) chain() []Middleware {
+	if !h.debug {
+		return h.middlewares
+	}
+
+	mws := make([]Middleware, 0, len(h.middlewares)+1)
+	mws = append(mws, h.middlewares...)
+	return append(mws, LoggingMiddleware(h.hLog))
 }
 
 // DoEnvelope makes an HTTP request and parses the JSON response (fastglue envelop structure)
-func (h *httpClient) DoEnvelope(method, url string, params url.Values, headers http.Header, obj interface{}) error {
-	resp, err := h.Do(method, url, params, headers)
+func (h *httpClient) DoEnvelope(ctx context.Context, method, url string, params url.Values, headers http.Header, obj interface{}) error {
+	resp, err := h.Do(ctx, method, url, params, headers)
 	if err != nil {
 		return err
 	}
@@ -156,12 +330,31 @@ func (h *httpClient) DoEnvelope(method, url string, params url.Values, headers h
 	return err
 }
 
+// readEnvelope parses the JSON envelope of a response into obj. Errors carry
+// the status code of the response and the request it was made for.
 func readEnvelope(resp HTTPResponse, obj interface{}) error {
+	if err := parseEnvelope(resp, obj); err != nil {
+		e, ok := err.(Error)
+		if !ok {
+			return err
+		}
+
+		e.Status = resp.Response.StatusCode
+		if req := resp.Response.Request; req != nil {
+			e.Method, e.Path = req.Method, req.URL.Path
+		}
+		return e
+	}
+
+	return nil
+}
+
+func parseEnvelope(resp HTTPResponse, obj interface{}) error {
 	// Successful request, but error envelope.
 	if resp.Response.StatusCode >= http.StatusBadRequest {
 		var e errorEnvelope
 		if err := json.Unmarshal(resp.Body, &e); err != nil {
-			return NewError(DataError, "Error parsing response.", nil)
+			return wrapError(DataError, "Error parsing response", err)
 		}
 
 		return newError(e.ErrorType, e.Message, resp.Response.StatusCode, e.Data)
@@ -172,15 +365,15 @@ func readEnvelope(resp HTTPResponse, obj interface{}) error {
 	envl.Data = obj
 
 	if err := json.Unmarshal(resp.Body, &envl); err != nil {
-		return NewError(DataError, "Error parsing response.", nil)
+		return wrapError(DataError, "Error parsing response", err)
 	}
 
 	return nil
 }
 
 // DoJSON makes an HTTP request and parses the JSON response.
-func (h *httpClient) DoJSON(method, url string, params url.Values, headers http.Header, obj interface{}) (HTTPResponse, error) {
-	resp, err := h.Do(method, url, params, headers)
+func (h *httpClient) DoJSON(ctx context.Context, method, url string, params url.Values, headers http.Header, obj interface{}) (HTTPResponse, error) {
+	resp, err := h.Do(ctx, method, url, params, headers)
 	if err != nil {
 		return resp, err
 	}
@@ -188,12 +381,23 @@ func (h *httpClient) DoJSON(method, url string, params url.Values, headers http.
 	// We now unmarshal the body.
 	if err := json.Unmarshal(resp.Body, &obj); err != nil {
 		h.hLog.Printf("Error parsing JSON response: %v | %s", err, resp.Body)
-		return resp, NewError(DataError, "Error parsing response.", nil)
+		return resp, withRequest(wrapError(DataError, "Error parsing response", err), method, url)
 	}
 
 	return resp, nil
 }
 
+// SetRetryPolicy sets the policy used to retry failed requests.
+func (h *httpClient) SetRetryPolicy(p RetryPolicy) {
+	h.retry = p
+}
+
+// Use appends middlewares to the request chain. BeforeRequest hooks run in
+// the order they were added and the response hooks in reverse order.
+func (h *httpClient) Use(mws ...Middleware) {
+	h.middlewares = append(h.middlewares, mws...)
+}
+
 // GetClient return's the underlying net/http client.
 func (h *httpClient) GetClient() *httpClient {
 	return h
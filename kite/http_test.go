@@ -0,0 +1,55 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoEnvelopeContextCancelled(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	defer close(done)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := kc.GetOrdersWithContext(ctx)
+	require.Error(t, err)
+
+	kerr, ok := err.(Error)
+	require.True(t, ok)
+	require.Equal(t, ContextError, kerr.ErrorType)
+}
+
+func TestDoEnvelopeWithContext(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":[{"order_id":"1"}]}`))
+	}))
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+
+	orders, err := kc.GetOrdersWithContext(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, len(orders))
+	require.Equal(t, "1", orders[0].OrderID)
+}
@@ -0,0 +1,386 @@
+package kite
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// The instruments dump is regenerated every morning. Dumps fetched before
+// this time of day (IST) belong to the previous trading day.
+const (
+	instrumentsRefreshHour   = 8
+	instrumentsRefreshMinute = 30
+	instrumentsCachePrefix   = "instruments-"
+	instrumentsCacheLayout   = "2006-01-02"
+)
+
+// contractKey identifies a derivative contract of an underlying on an
+// exchange, as the same contract may be listed on several.
+type contractKey struct {
+	exchange       string
+	name           string
+	expiry         string
+	strike         float64
+	instrumentType string
+}
+
+// InstrumentFilter selects instruments in InstrumentMaster.Query. Empty
+// fields match all instruments.
+type InstrumentFilter struct {
+	Exchange        string
+	Segment         string
+	Name            string
+	InstrumentTypes []string
+	// Expiry matches instruments expiring on the same day.
+	Expiry time.Time
+}
+
+// Match reports whether inst matches f. It can be passed to
+// Client.GetInstrumentsMatching to filter the dump as it is downloaded.
+func (f InstrumentFilter) Match(inst Instrument) bool {
+	if f.Exchange != "" && inst.Exchange != f.Exchange {
+		return false
+	}
+
+	if f.Segment != "" && inst.Segment != f.Segment {
+		return false
+	}
+
+	if f.Name != "" && inst.Name != f.Name {
+		return false
+	}
+
+	if len(f.InstrumentTypes) > 0 && !containsString(f.InstrumentTypes, inst.InstrumentType) {
+		return false
+	}
+
+	if !f.Expiry.IsZero() && (inst.Expiry.IsZero() || !sameDay(inst.Expiry.Time, f.Expiry)) {
+		return false
+	}
+
+	return true
+}
+
+// sameDay reports whether a and b fall on the same day in IST.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.In(istLocation()).Date()
+	by, bm, bd := b.In(istLocation()).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// InstrumentMaster holds the instruments dump in memory, indexed for
+// lookups by token, tradingsymbol and contract. The dump is downloaded at
+// most once per trading day and, if a cache directory is set, shared across
+// restarts through a CSV file in it.
+//
+//	im := kite.NewInstrumentMaster(kc, os.TempDir())
+//	if err := im.Load(ctx); err != nil {
+//		return err
+//	}
+//	infy, ok := im.BySymbol(kite.ExchangeNSE, "INFY")
+type InstrumentMaster struct {
+	client   *Client
+	cacheDir string
+	now      func() time.Time
+	onChange func(changes []InstrumentChange)
+
+	// loadMu serializes loads, so concurrent callers download the dump once.
+	loadMu sync.Mutex
+
+	mu          sync.RWMutex
+	day         string
+	instruments Instruments
+	byToken     map[int]int
+	bySymbol    map[string]int
+	byContract  map[contractKey]int
+	byName      map[string][]int
+}
+
+// NewInstrumentMaster returns an instrument master which downloads the dump
+// with c. cacheDir may be empty to disable the disk cache.
+func NewInstrumentMaster(c *Client, cacheDir string) *InstrumentMaster {
+	return &InstrumentMaster{
+		client:   c,
+		cacheDir: cacheDir,
+		now:      time.Now,
+	}
+}
+
+// OnChange sets a callback which is called with the changes between the
+// loaded instruments and those of a newer dump, whenever one replaces them.
+// It isn't called for the first load. f runs within the load, so it must
+// not call Load or Refresh.
+func (m *InstrumentMaster) OnChange(f func(changes []InstrumentChange)) {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+	m.onChange = f
+}
+
+// instrumentsDay returns the trading day of the instruments dump at t.
+func instrumentsDay(t time.Time) string {
+	t = t.In(istLocation())
+	refresh := time.Date(t.Year(), t.Month(), t.Day(), instrumentsRefreshHour, instrumentsRefreshMinute, 0, 0, t.Location())
+	if t.Before(refresh) {
+		t = t.AddDate(0, 0, -1)
+	}
+
+	return t.Format(instrumentsCacheLayout)
+}
+
+// Load loads the instruments of the current trading day, from the disk cache
+// if it has them or else from the API. It does nothing if they are already
+// loaded.
+func (m *InstrumentMaster) Load(ctx context.Context) error {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+
+	day := instrumentsDay(m.now())
+
+	m.mu.RLock()
+	loaded := m.day == day
+	m.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	if m.cacheDir != "" {
+		// A corrupt cache file is replaced by a fresh download.
+		if f, err := os.Open(m.cachePath(day)); err == nil {
+			var instruments Instruments
+			err := decodeInstruments(f, collectInstruments(&instruments, nil))
+			f.Close()
+			if err == nil {
+				m.index(day, instruments)
+				return nil
+			}
+		}
+	}
+
+	return m.fetch(ctx, day)
+}
+
+// Refresh downloads the instruments from the API, even if the current
+// trading day's instruments are already loaded.
+func (m *InstrumentMaster) Refresh(ctx context.Context) error {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+
+	return m.fetch(ctx, instrumentsDay(m.now()))
+}
+
+// fetch streams the dump from the API, writing it to the cache directory as
+// it is decoded.
+func (m *InstrumentMaster) fetch(ctx context.Context, day string) error {
+	var tmp *os.File
+	if m.cacheDir != "" {
+		if err := os.MkdirAll(m.cacheDir, 0755); err != nil {
+			return err
+		}
+
+		f, err := ioutil.TempFile(m.cacheDir, "."+instrumentsCachePrefix+"*")
+		if err != nil {
+			return err
+		}
+		tmp = f
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+	}
+
+	var instruments Instruments
+	err := m.client.streamCSV(ctx, URIGetInstruments, func(r io.Reader) error {
+		if tmp != nil {
+			r = io.TeeReader(r, tmp)
+		}
+		return decodeInstruments(r, collectInstruments(&instruments, nil))
+	})
+	if err != nil {
+		return err
+	}
+
+	m.index(day, instruments)
+
+	if tmp != nil {
+		return m.commitCache(day, tmp)
+	}
+
+	return nil
+}
+
+// index swaps in the instruments of day and their indexes, and reports the
+// changes from the instruments they replace.
+func (m *InstrumentMaster) index(day string, instruments Instruments) {
+	byToken := make(map[int]int, len(instruments))
+	bySymbol := make(map[string]int, len(instruments))
+	byContract := make(map[contractKey]int)
+	byName := make(map[string][]int)
+
+	for i, inst := range instruments {
+		byToken[inst.InstrumentToken] = i
+		bySymbol[inst.Exchange+":"+inst.Tradingsymbol] = i
+
+		if inst.Name == "" {
+			continue
+		}
+		byName[inst.Name] = append(byName[inst.Name], i)
+
+		if !inst.Expiry.IsZero() {
+			byContract[newContractKey(inst.Exchange, inst.Name, inst.Expiry.Time, inst.StrikePrice, inst.InstrumentType)] = i
+		}
+	}
+
+	m.mu.Lock()
+	prev := m.instruments
+	m.day = day
+	m.instruments = instruments
+	m.byToken = byToken
+	m.bySymbol = bySymbol
+	m.byContract = byContract
+	m.byName = byName
+	m.mu.Unlock()
+
+	if m.onChange != nil && prev != nil {
+		if changes := DiffInstruments(prev, instruments); len(changes) > 0 {
+			m.onChange(changes)
+		}
+	}
+}
+
+func (m *InstrumentMaster) cachePath(day string) string {
+	return filepath.Join(m.cacheDir, instrumentsCachePrefix+day+".csv")
+}
+
+// commitCache atomically moves the downloaded dump of day into place and
+// removes the dumps of earlier days.
+func (m *InstrumentMaster) commitCache(day string, tmp *os.File) error {
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	path := m.cachePath(day)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	old, _ := filepath.Glob(filepath.Join(m.cacheDir, instrumentsCachePrefix+"*.csv"))
+	for _, o := range old {
+		if o != path {
+			os.Remove(o)
+		}
+	}
+
+	return nil
+}
+
+func newContractKey(exchange, name string, expiry time.Time, strike float64, instrumentType string) contractKey {
+	return contractKey{
+		exchange:       exchange,
+		name:           name,
+		expiry:         expiry.In(istLocation()).Format(instrumentsCacheLayout),
+		strike:         strike,
+		instrumentType: instrumentType,
+	}
+}
+
+// TradingDay returns the trading day of the loaded instruments as
+// YYYY-MM-DD, or an empty string if none are loaded.
+func (m *InstrumentMaster) TradingDay() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.day
+}
+
+// Instruments returns all the loaded instruments.
+func (m *InstrumentMaster) Instruments() Instruments {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append(Instruments(nil), m.instruments...)
+}
+
+// ByToken returns the instrument with the given instrument token.
+func (m *InstrumentMaster) ByToken(instrumentToken int) (Instrument, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i, ok := m.byToken[instrumentToken]
+	if !ok {
+		return Instrument{}, false
+	}
+
+	return m.instruments[i], true
+}
+
+// BySymbol returns the instrument with the given exchange and tradingsymbol.
+func (m *InstrumentMaster) BySymbol(exchange, tradingsymbol string) (Instrument, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i, ok := m.bySymbol[exchange+":"+tradingsymbol]
+	if !ok {
+		return Instrument{}, false
+	}
+
+	return m.instruments[i], true
+}
+
+// ByContract returns the derivative contract of the underlying name listed
+// on exchange with the given expiry day, strike and instrument type (CE, PE
+// or FUT). Futures have a strike of 0.
+func (m *InstrumentMaster) ByContract(exchange, name string, expiry time.Time, strike float64, instrumentType string) (Instrument, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i, ok := m.byContract[newContractKey(exchange, name, expiry, strike, instrumentType)]
+	if !ok {
+		return Instrument{}, false
+	}
+
+	return m.instruments[i], true
+}
+
+// Query returns the instruments matching f, in the order of the dump.
+//
+//	// All NFO options of NIFTY expiring on 20 Jul 2023.
+//	opts := im.Query(kite.InstrumentFilter{
+//		Exchange:        kite.ExchangeNFO,
+//		Name:            "NIFTY",
+//		InstrumentTypes: []string{"CE", "PE"},
+//		Expiry:          time.Date(2023, 7, 20, 0, 0, 0, 0, time.Local),
+//	})
+func (m *InstrumentMaster) Query(f InstrumentFilter) Instruments {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out Instruments
+	if f.Name != "" {
+		for _, i := range m.byName[f.Name] {
+			if f.Match(m.instruments[i]) {
+				out = append(out, m.instruments[i])
+			}
+		}
+
+		return out
+	}
+
+	for _, inst := range m.instruments {
+		if f.Match(inst) {
+			out = append(out, inst)
+		}
+	}
+
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package kite
+
+// InstrumentChangeType is the kind of change between two instrument
+// snapshots.
+type InstrumentChangeType string
+
+// Instrument change types.
+const (
+	InstrumentAdded           InstrumentChangeType = "added"
+	InstrumentRemoved         InstrumentChangeType = "removed"
+	InstrumentLotSizeChanged  InstrumentChangeType = "lot_size"
+	InstrumentTickSizeChanged InstrumentChangeType = "tick_size"
+	InstrumentExpiryMoved     InstrumentChangeType = "expiry"
+)
+
+// InstrumentChange is a change to an instrument between two snapshots. Old
+// is empty for added instruments and New for removed ones.
+type InstrumentChange struct {
+	Type InstrumentChangeType
+	Old  Instrument
+	New  Instrument
+}
+
+// Token returns the instrument token the change applies to.
+func (c InstrumentChange) Token() int {
+	if c.Type == InstrumentRemoved {
+		return c.Old.InstrumentToken
+	}
+
+	return c.New.InstrumentToken
+}
+
+// DiffInstruments compares two instrument snapshots, such as the dumps of
+// consecutive trading days. Instruments are matched by instrument token; a
+// token which was reused for a different contract is reported as removed
+// and added. An instrument whose lot size and tick size both changed
+// yields a change of each type.
+//
+// Removals come first in the order of prev, followed by the other changes
+// in the order of next.
+func DiffInstruments(prev, next Instruments) []InstrumentChange {
+	nextByToken := make(map[int]int, len(next))
+	for i, inst := range next {
+		nextByToken[inst.InstrumentToken] = i
+	}
+
+	prevByToken := make(map[int]int, len(prev))
+	var changes []InstrumentChange
+	for i, old := range prev {
+		prevByToken[old.InstrumentToken] = i
+		if j, ok := nextByToken[old.InstrumentToken]; !ok || !sameContract(old, next[j]) {
+			changes = append(changes, InstrumentChange{Type: InstrumentRemoved, Old: old})
+		}
+	}
+
+	for _, inst := range next {
+		i, ok := prevByToken[inst.InstrumentToken]
+		if !ok || !sameContract(prev[i], inst) {
+			changes = append(changes, InstrumentChange{Type: InstrumentAdded, New: inst})
+			continue
+		}
+
+		old := prev[i]
+		if old.LotSize != inst.LotSize {
+			changes = append(changes, InstrumentChange{Type: InstrumentLotSizeChanged, Old: old, New: inst})
+		}
+
+		if old.TickSize != inst.TickSize {
+			changes = append(changes, InstrumentChange{Type: InstrumentTickSizeChanged, Old: old, New: inst})
+		}
+
+		if !old.Expiry.Equal(inst.Expiry.Time) {
+			changes = append(changes, InstrumentChange{Type: InstrumentExpiryMoved, Old: old, New: inst})
+		}
+	}
+
+	return changes
+}
+
+// sameContract reports whether two instruments with the same token are the
+// same contract.
+func sameContract(a, b Instrument) bool {
+	return a.Exchange == b.Exchange && a.Tradingsymbol == b.Tradingsymbol
+}
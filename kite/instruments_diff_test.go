@@ -0,0 +1,102 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffInstruments(t *testing.T) {
+	t.Parallel()
+	ist := istLocation()
+	thu := Time{Time: time.Date(2023, 7, 20, 0, 0, 0, 0, ist)}
+	wed := Time{Time: time.Date(2023, 7, 19, 0, 0, 0, 0, ist)}
+
+	prev := Instruments{
+		{InstrumentToken: 1, Exchange: ExchangeNSE, Tradingsymbol: "INFY", LotSize: 1, TickSize: 0.05},
+		{InstrumentToken: 2, Exchange: ExchangeNFO, Tradingsymbol: "NIFTY2371319500CE", LotSize: 50, TickSize: 0.05},
+		{InstrumentToken: 3, Exchange: ExchangeNFO, Tradingsymbol: "NIFTY2372019500CE", LotSize: 50, TickSize: 0.05, Expiry: thu},
+		{InstrumentToken: 4, Exchange: ExchangeNFO, Tradingsymbol: "BANKNIFTY23JULFUT", LotSize: 25, TickSize: 0.05},
+		{InstrumentToken: 5, Exchange: ExchangeNFO, Tradingsymbol: "FINNIFTY23JULFUT", LotSize: 40, TickSize: 0.05},
+	}
+	next := Instruments{
+		{InstrumentToken: 1, Exchange: ExchangeNSE, Tradingsymbol: "INFY", LotSize: 1, TickSize: 0.05},
+		{InstrumentToken: 3, Exchange: ExchangeNFO, Tradingsymbol: "NIFTY2372019500CE", LotSize: 50, TickSize: 0.05, Expiry: wed},
+		{InstrumentToken: 4, Exchange: ExchangeNFO, Tradingsymbol: "BANKNIFTY23JULFUT", LotSize: 15, TickSize: 0.1},
+		// Token 5 reused for a new contract.
+		{InstrumentToken: 5, Exchange: ExchangeNFO, Tradingsymbol: "NIFTY2372719500CE", LotSize: 50, TickSize: 0.05},
+		{InstrumentToken: 6, Exchange: ExchangeNFO, Tradingsymbol: "NIFTY2372719600CE", LotSize: 50, TickSize: 0.05},
+	}
+
+	var got []string
+	for _, c := range DiffInstruments(prev, next) {
+		sym := c.New.Tradingsymbol
+		if c.Type == InstrumentRemoved {
+			sym = c.Old.Tradingsymbol
+		}
+		got = append(got, string(c.Type)+" "+sym)
+	}
+
+	require.Equal(t, []string{
+		"removed NIFTY2371319500CE",
+		"removed FINNIFTY23JULFUT",
+		"expiry NIFTY2372019500CE",
+		"lot_size BANKNIFTY23JULFUT",
+		"tick_size BANKNIFTY23JULFUT",
+		"added NIFTY2372719500CE",
+		"added NIFTY2372719600CE",
+	}, got)
+
+	require.Nil(t, DiffInstruments(next, next))
+
+	c := DiffInstruments(prev[:1], nil)[0]
+	require.Equal(t, 1, c.Token())
+}
+
+func TestInstrumentMasterOnChange(t *testing.T) {
+	t.Parallel()
+	var body atomic.Value
+	body.Store(instrumentsCSV)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.Load().(string)))
+	}))
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	im := NewInstrumentMaster(kc, "")
+
+	var changes []InstrumentChange
+	im.OnChange(func(c []InstrumentChange) {
+		changes = append(changes, c...)
+	})
+
+	require.Nil(t, im.Load(context.Background()))
+	require.Nil(t, changes)
+
+	// The next dump drops INFY and halves the NIFTY lot size.
+	lines := strings.Split(instrumentsCSV, "\n")
+	next := []string{lines[0], lines[2], strings.Replace(lines[3], ",50,", ",25,", 1)}
+	body.Store(strings.Join(next, "\n") + "\n")
+
+	require.Nil(t, im.Refresh(context.Background()))
+	require.Equal(t, InstrumentRemoved, changes[0].Type)
+	require.Equal(t, 408065, changes[0].Token())
+
+	var lotSize []InstrumentChange
+	for _, c := range changes {
+		if c.Type == InstrumentLotSizeChanged {
+			lotSize = append(lotSize, c)
+		}
+	}
+	require.Equal(t, 1, len(lotSize))
+	require.Equal(t, "NIFTY23JULFUT", lotSize[0].New.Tradingsymbol)
+	require.Equal(t, 50.0, lotSize[0].Old.LotSize)
+	require.Equal(t, 25.0, lotSize[0].New.LotSize)
+}
@@ -0,0 +1,160 @@
+package kite
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const instrumentsCSV = `instrument_token,exchange_token,tradingsymbol,name,last_price,expiry,strike,tick_size,lot_size,instrument_type,segment,exchange
+408065,1594,INFY,INFOSYS,0,,0,0.05,1,EQ,NSE,NSE
+256265,0,NIFTY 50,NIFTY 50,0,,0,0,0,EQ,INDICES,NSE
+13238786,51714,NIFTY23JULFUT,NIFTY,0,2023-07-27,0,0.05,50,FUT,NFO-FUT,NFO
+10631426,41529,NIFTY2372019500CE,NIFTY,0,2023-07-20,19500,0.05,50,CE,NFO-OPT,NFO
+10631682,41530,NIFTY2372019500PE,NIFTY,0,2023-07-20,19500,0.05,50,PE,NFO-OPT,NFO
+10632706,41534,NIFTY2372019600CE,NIFTY,0,2023-07-20,19600,0.05,50,CE,NFO-OPT,NFO
+12079106,47184,NIFTY23JUL19500CE,NIFTY,0,2023-07-27,19500,0.05,50,CE,NFO-OPT,NFO
+14350850,56058,BANKNIFTY2372044900CE,BANKNIFTY,0,2023-07-20,44900,0.05,25,CE,NFO-OPT,NFO
+`
+
+// instrumentsServer serves instrumentsCSV and counts the downloads.
+func instrumentsServer() (*httptest.Server, *int32) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(instrumentsCSV))
+	}))
+
+	return ts, &hits
+}
+
+func TestInstrumentMasterContractExchanges(t *testing.T) {
+	t.Parallel()
+	expiry := Time{Time: time.Date(2023, 7, 28, 0, 0, 0, 0, istLocation())}
+	im := NewInstrumentMaster(nil, "")
+	im.index("2023-07-14", Instruments{
+		{InstrumentToken: 1, Tradingsymbol: "USDINR23JULFUT", Name: "USDINR", Expiry: expiry, InstrumentType: "FUT", Exchange: ExchangeCDS},
+		{InstrumentToken: 2, Tradingsymbol: "USDINR23JULFUT", Name: "USDINR", Expiry: expiry, InstrumentType: "FUT", Exchange: ExchangeBCD},
+	})
+
+	for token, exchange := range map[int]string{1: ExchangeCDS, 2: ExchangeBCD} {
+		i, ok := im.ByContract(exchange, "USDINR", expiry.Time, 0, "FUT")
+		require.True(t, ok)
+		require.Equal(t, token, i.InstrumentToken)
+	}
+
+	_, ok := im.ByContract(ExchangeNFO, "USDINR", expiry.Time, 0, "FUT")
+	require.False(t, ok)
+}
+
+func TestInstrumentsDay(t *testing.T) {
+	t.Parallel()
+	ist := istLocation()
+	require.Equal(t, "2023-07-13", instrumentsDay(time.Date(2023, 7, 14, 8, 29, 0, 0, ist)))
+	require.Equal(t, "2023-07-14", instrumentsDay(time.Date(2023, 7, 14, 8, 30, 0, 0, ist)))
+	// 03:30 UTC is 09:00 IST.
+	require.Equal(t, "2023-07-14", instrumentsDay(time.Date(2023, 7, 14, 3, 30, 0, 0, time.UTC)))
+}
+
+func TestInstrumentMasterLookup(t *testing.T) {
+	t.Parallel()
+	ts, _ := instrumentsServer()
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	im := NewInstrumentMaster(kc, "")
+	require.Nil(t, im.Load(context.Background()))
+	require.Equal(t, 8, len(im.Instruments()))
+
+	i, ok := im.ByToken(408065)
+	require.True(t, ok)
+	require.Equal(t, "INFY", i.Tradingsymbol)
+
+	i, ok = im.BySymbol(ExchangeNFO, "NIFTY2372019500PE")
+	require.True(t, ok)
+	require.Equal(t, 10631682, i.InstrumentToken)
+
+	_, ok = im.BySymbol(ExchangeNSE, "NIFTY2372019500PE")
+	require.False(t, ok)
+
+	expiry := time.Date(2023, 7, 20, 0, 0, 0, 0, istLocation())
+	i, ok = im.ByContract(ExchangeNFO, "NIFTY", expiry, 19600, "CE")
+	require.True(t, ok)
+	require.Equal(t, "NIFTY2372019600CE", i.Tradingsymbol)
+
+	i, ok = im.ByContract(ExchangeNFO, "NIFTY", time.Date(2023, 7, 27, 0, 0, 0, 0, istLocation()), 0, "FUT")
+	require.True(t, ok)
+	require.Equal(t, "NIFTY23JULFUT", i.Tradingsymbol)
+
+	_, ok = im.ByContract(ExchangeNFO, "NIFTY", expiry, 19700, "CE")
+	require.False(t, ok)
+
+	_, ok = im.ByContract(ExchangeBFO, "NIFTY", expiry, 19600, "CE")
+	require.False(t, ok)
+
+	opts := im.Query(InstrumentFilter{
+		Exchange:        ExchangeNFO,
+		Name:            "NIFTY",
+		InstrumentTypes: []string{"CE", "PE"},
+		Expiry:          expiry,
+	})
+	require.Equal(t, 3, len(opts))
+	for _, o := range opts {
+		require.Equal(t, "NIFTY", o.Name)
+		require.Equal(t, "NFO-OPT", o.Segment)
+	}
+
+	require.Equal(t, 2, len(im.Query(InstrumentFilter{Exchange: ExchangeNSE})))
+	require.Equal(t, 1, len(im.Query(InstrumentFilter{Segment: "NFO-FUT"})))
+}
+
+func TestInstrumentMasterCache(t *testing.T) {
+	t.Parallel()
+	ts, hits := instrumentsServer()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "kite")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+
+	now := time.Date(2023, 7, 14, 9, 0, 0, 0, istLocation())
+	im := NewInstrumentMaster(kc, dir)
+	im.now = func() time.Time { return now }
+
+	require.Nil(t, im.Load(context.Background()))
+	require.Nil(t, im.Load(context.Background()))
+	require.Equal(t, int32(1), atomic.LoadInt32(hits))
+	require.Equal(t, "2023-07-14", im.TradingDay())
+
+	// A restarted process loads from the disk cache.
+	im2 := NewInstrumentMaster(kc, dir)
+	im2.now = im.now
+	require.Nil(t, im2.Load(context.Background()))
+	require.Equal(t, int32(1), atomic.LoadInt32(hits))
+	_, ok := im2.BySymbol(ExchangeNSE, "INFY")
+	require.True(t, ok)
+
+	// The next trading day's dump is downloaded and replaces the cache.
+	now = now.AddDate(0, 0, 1)
+	require.Nil(t, im.Load(context.Background()))
+	require.Equal(t, int32(2), atomic.LoadInt32(hits))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.Nil(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "instruments-2023-07-15.csv")}, files)
+
+	require.Nil(t, im.Refresh(context.Background()))
+	require.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
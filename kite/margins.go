@@ -1,6 +1,7 @@
 package kite
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -64,34 +65,11 @@ type GetBasketParams struct {
 
 // GetOrderMargins -
 func (c *Client) GetOrderMargins(marparam GetMarginParams) ([]OrderMargins, error) {
-	body, err := json.Marshal(marparam.OrderParams)
-	if err != nil {
-		return []OrderMargins{}, err
-	}
-
-	var headers http.Header = map[string][]string{}
-	headers.Add("Content-Type", "application/json")
-
-	uri := URIOrderMargins
-	if marparam.Compact {
-		uri += "?mode=compact"
-	}
-
-	resp, err := c.doRaw(http.MethodPost, uri, body, headers)
-	if err != nil {
-		return []OrderMargins{}, err
-	}
-
-	var out []OrderMargins
-	if err := readEnvelope(resp, &out); err != nil {
-		return []OrderMargins{}, err
-	}
-
-	return out, nil
+	return c.GetOrderMarginsWithContext(context.Background(), marparam)
 }
 
-// GetOrderMarginsOMS - get margin orders
-func (c *Client) GetOrderMarginsOMS(marparam GetMarginParams) ([]OrderMargins, error) {
+// GetOrderMarginsWithContext is the context aware variant of GetOrderMargins.
+func (c *Client) GetOrderMarginsWithContext(ctx context.Context, marparam GetMarginParams) ([]OrderMargins, error) {
 	body, err := json.Marshal(marparam.OrderParams)
 	if err != nil {
 		return []OrderMargins{}, err
@@ -100,18 +78,18 @@ func (c *Client) GetOrderMarginsOMS(marparam GetMarginParams) ([]OrderMargins, e
 	var headers http.Header = map[string][]string{}
 	headers.Add("Content-Type", "application/json")
 
-	uri := URIOrderMarginsOMS
+	uri := URIOrderMargins
 	if marparam.Compact {
 		uri += "?mode=compact"
 	}
 
-	resp, err := c.doRaw(http.MethodPost, uri, body, headers)
+	resp, err := c.doRaw(ctx, http.MethodPost, uri, body, headers)
 	if err != nil {
 		return []OrderMargins{}, err
 	}
 
 	var out []OrderMargins
-	if err := readEnvelope(resp, &out); err != nil {
+	if err := c.readEnvelope(resp, &out); err != nil {
 		return []OrderMargins{}, err
 	}
 
@@ -120,6 +98,11 @@ func (c *Client) GetOrderMarginsOMS(marparam GetMarginParams) ([]OrderMargins, e
 
 // GetBasketMargins -
 func (c *Client) GetBasketMargins(baskparam GetBasketParams) (BasketMargins, error) {
+	return c.GetBasketMarginsWithContext(context.Background(), baskparam)
+}
+
+// GetBasketMarginsWithContext is the context aware variant of GetBasketMargins.
+func (c *Client) GetBasketMarginsWithContext(ctx context.Context, baskparam GetBasketParams) (BasketMargins, error) {
 	body, err := json.Marshal(baskparam.OrderParams)
 	if err != nil {
 		return BasketMargins{}, err
@@ -141,13 +124,13 @@ func (c *Client) GetBasketMargins(baskparam GetBasketParams) (BasketMargins, err
 		uri += "?" + qp
 	}
 
-	resp, err := c.doRaw(http.MethodPost, uri, body, headers)
+	resp, err := c.doRaw(ctx, http.MethodPost, uri, body, headers)
 	if err != nil {
 		return BasketMargins{}, err
 	}
 
 	var out BasketMargins
-	if err := readEnvelope(resp, &out); err != nil {
+	if err := c.readEnvelope(resp, &out); err != nil {
 		return BasketMargins{}, err
 	}
 
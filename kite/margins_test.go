@@ -1,11 +1,15 @@
-package kite
+package kite_test
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/santoshanand/at-kite/kite"
+)
 
 func TestGetOrderMargins(t *testing.T) {
 	t.Parallel()
 
-	params := OrderMarginParam{
+	params := kite.OrderMarginParam{
 		Exchange:        "NSE",
 		Tradingsymbol:   "INFY",
 		TransactionType: "BUY",
@@ -17,8 +21,8 @@ func TestGetOrderMargins(t *testing.T) {
 		TriggerPrice:    0,
 	}
 
-	orderResponse, err := getKite().GetOrderMargins(GetMarginParams{
-		OrderParams: []OrderMarginParam{params},
+	orderResponse, err := getKite().GetOrderMargins(kite.GetMarginParams{
+		OrderParams: []kite.OrderMarginParam{params},
 		Compact:     true,
 	})
 	if err != nil {
@@ -37,7 +41,7 @@ func TestGetOrderMargins(t *testing.T) {
 func TestGetBasketMargins(t *testing.T) {
 	t.Parallel()
 
-	params := OrderMarginParam{
+	params := kite.OrderMarginParam{
 		Exchange:        "NSE",
 		Tradingsymbol:   "INFY",
 		TransactionType: "BUY",
@@ -49,8 +53,8 @@ func TestGetBasketMargins(t *testing.T) {
 		TriggerPrice:    0,
 	}
 
-	orderResponseBasket, err := getKite().GetBasketMargins(GetBasketParams{
-		OrderParams:       []OrderMarginParam{params},
+	orderResponseBasket, err := getKite().GetBasketMargins(kite.GetBasketParams{
+		OrderParams:       []kite.OrderMarginParam{params},
 		Compact:           true,
 		ConsiderPositions: true,
 	})
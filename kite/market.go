@@ -1,9 +1,13 @@
 package kite
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/gocarina/gocsv"
@@ -14,8 +18,8 @@ type quoteParams struct {
 	Instruments []string `url:"i"`
 }
 
-// Quote represents the full quote response.
-type Quote map[string]struct {
+// QuoteData is the full quote of an instrument.
+type QuoteData struct {
 	InstrumentToken   int     `json:"instrument_token"`
 	Timestamp         Time    `json:"timestamp"`
 	LastPrice         float64 `json:"last_price"`
@@ -35,19 +39,95 @@ type Quote map[string]struct {
 	Depth             Depth   `json:"depth"`
 }
 
-// QuoteOHLC represents OHLC quote response.
-type QuoteOHLC map[string]struct {
+// Quote represents the full quote response, keyed by the instrument as it
+// was requested.
+type Quote map[string]QuoteData
+
+// ByToken returns the quote of the instrument with the given token. It
+// scans all the quotes, so use IndexByToken for more than a few lookups.
+func (q Quote) ByToken(instrumentToken int) (QuoteData, bool) {
+	for _, d := range q {
+		if d.InstrumentToken == instrumentToken {
+			return d, true
+		}
+	}
+
+	return QuoteData{}, false
+}
+
+// IndexByToken returns the quotes keyed by instrument token.
+func (q Quote) IndexByToken() map[int]QuoteData {
+	out := make(map[int]QuoteData, len(q))
+	for _, d := range q {
+		out[d.InstrumentToken] = d
+	}
+
+	return out
+}
+
+// OHLCData is the OHLC quote of an instrument.
+type OHLCData struct {
 	InstrumentToken int     `json:"instrument_token"`
 	LastPrice       float64 `json:"last_price"`
 	OHLC            OHLC    `json:"ohlc"`
 }
 
-// QuoteLTP represents last price quote response.
-type QuoteLTP map[string]struct {
+// QuoteOHLC represents OHLC quote response.
+type QuoteOHLC map[string]OHLCData
+
+// ByToken returns the OHLC quote of the instrument with the given token. It
+// scans all the quotes, so use IndexByToken for more than a few lookups.
+func (q QuoteOHLC) ByToken(instrumentToken int) (OHLCData, bool) {
+	for _, d := range q {
+		if d.InstrumentToken == instrumentToken {
+			return d, true
+		}
+	}
+
+	return OHLCData{}, false
+}
+
+// IndexByToken returns the OHLC quotes keyed by instrument token.
+func (q QuoteOHLC) IndexByToken() map[int]OHLCData {
+	out := make(map[int]OHLCData, len(q))
+	for _, d := range q {
+		out[d.InstrumentToken] = d
+	}
+
+	return out
+}
+
+// LTPData is the last price of an instrument.
+type LTPData struct {
 	InstrumentToken int     `json:"instrument_token"`
 	LastPrice       float64 `json:"last_price"`
 }
 
+// QuoteLTP represents last price quote response.
+type QuoteLTP map[string]LTPData
+
+// ByToken returns the last price of the instrument with the given token. It
+// scans all the quotes, so use IndexByToken for more than a few lookups.
+func (q QuoteLTP) ByToken(instrumentToken int) (LTPData, bool) {
+	for _, d := range q {
+		if d.InstrumentToken == instrumentToken {
+			return d, true
+		}
+	}
+
+	return LTPData{}, false
+}
+
+// IndexByToken returns the last prices keyed by instrument token.
+func (q QuoteLTP) IndexByToken() map[int]LTPData {
+	out := make(map[int]LTPData, len(q))
+	for _, d := range q {
+		out[d.InstrumentToken] = d
+	}
+
+	return out
+}
+
 // HistoricalData represents individual historical data response.
 type HistoricalData struct {
 	Date   Time    `json:"date"`
@@ -116,108 +196,207 @@ type MFInstrument struct {
 type MFInstruments []MFInstrument
 
 // GetQuote gets map of quotes for given instruments in the format of `exchange:tradingsymbol`.
+// Lists longer than the endpoint accepts are fetched in concurrent batches,
+// and the failure of some of them is reported with a PartialQuoteError.
 func (c *Client) GetQuote(instruments ...string) (Quote, error) {
-	var (
-		err     error
-		quotes  Quote
-		params  url.Values
-		qParams quoteParams
-	)
+	return c.GetQuoteWithContext(context.Background(), instruments...)
+}
 
-	qParams = quoteParams{
-		Instruments: instruments,
-	}
+// GetQuoteWithContext is the context aware variant of GetQuote.
+func (c *Client) GetQuoteWithContext(ctx context.Context, instruments ...string) (Quote, error) {
+	quotes := Quote{}
+	err := c.fetchQuotes(ctx, URIGetQuote, instruments, quotes)
+	return quotes, err
+}
 
-	if params, err = query.Values(qParams); err != nil {
-		return quotes, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
-	}
+// GetLTP gets map of LTP quotes for given instruments in the format of `exchange:tradingsymbol`.
+// Lists longer than the endpoint accepts are fetched in concurrent batches,
+// and the failure of some of them is reported with a PartialQuoteError.
+func (c *Client) GetLTP(instruments ...string) (QuoteLTP, error) {
+	return c.GetLTPWithContext(context.Background(), instruments...)
+}
 
-	err = c.doEnvelope(http.MethodGet, URIGetQuote, params, nil, &quotes)
+// GetLTPWithContext is the context aware variant of GetLTP.
+func (c *Client) GetLTPWithContext(ctx context.Context, instruments ...string) (QuoteLTP, error) {
+	quotes := QuoteLTP{}
+	err := c.fetchQuotes(ctx, URIGetLTP, instruments, quotes)
 	return quotes, err
 }
 
-// GetQuoteOMS gets map of quotes for given instruments in the format of `exchange:tradingsymbol`.
-func (c *Client) GetQuoteOMS(instruments ...string) (Quote, error) {
-	var (
-		err     error
-		quotes  Quote
-		params  url.Values
-		qParams quoteParams
-	)
+// GetOHLC gets map of OHLC quotes for given instruments in the format of `exchange:tradingsymbol`.
+// Lists longer than the endpoint accepts are fetched in concurrent batches,
+// and the failure of some of them is reported with a PartialQuoteError.
+func (c *Client) GetOHLC(instruments ...string) (QuoteOHLC, error) {
+	return c.GetOHLCWithContext(context.Background(), instruments...)
+}
 
-	qParams = quoteParams{
-		Instruments: instruments,
+// GetOHLCWithContext is the context aware variant of GetOHLC.
+func (c *Client) GetOHLCWithContext(ctx context.Context, instruments ...string) (QuoteOHLC, error) {
+	quotes := QuoteOHLC{}
+	err := c.fetchQuotes(ctx, URIGetOHLC, instruments, quotes)
+	return quotes, err
+}
+
+// Instruments accepted by a single request to the quote endpoints.
+var quoteLimits = map[string]int{
+	URIGetQuote: 500,
+	URIGetLTP:   1000,
+	URIGetOHLC:  1000,
+}
+
+// QuoteBatchError is a failed request for a batch of instruments.
+type QuoteBatchError struct {
+	Instruments []string
+	Err         error
+}
+
+// PartialQuoteError is returned by the quote methods when some of the
+// batches a long list of instruments was split into failed. The quotes of
+// the other batches are returned along with it.
+type PartialQuoteError struct {
+	Failed []QuoteBatchError
+}
+
+func (e PartialQuoteError) Error() string {
+	n := 0
+	for _, f := range e.Failed {
+		n += len(f.Instruments)
 	}
 
-	if params, err = query.Values(qParams); err != nil {
-		return quotes, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
+	return fmt.Sprintf("quotes failed for %d instruments: %v", n, e.Failed[0].Err)
+}
+
+// Unwrap returns the error of the first failed batch.
+func (e PartialQuoteError) Unwrap() error {
+	return e.Failed[0].Err
+}
+
+// Instruments returns the instruments which have no quotes due to a failed
+// batch.
+func (e PartialQuoteError) Instruments() []string {
+	var out []string
+	for _, f := range e.Failed {
+		out = append(out, f.Instruments...)
 	}
 
-	err = c.doEnvelope(http.MethodGet, URIGetQuoteOMS, params, nil, &quotes)
-	return quotes, err
+	return out
 }
 
-// GetLTP gets map of LTP quotes for given instruments in the format of `exchange:tradingsymbol`.
-func (c *Client) GetLTP(instruments ...string) (QuoteLTP, error) {
+// fetchQuotes requests the quotes of instruments from the quote endpoint
+// uri in batches, and adds them to quotes, a Quote, QuoteLTP or QuoteOHLC.
+func (c *Client) fetchQuotes(ctx context.Context, uri string, instruments []string, quotes interface{}) error {
 	var (
-		err     error
-		quotes  QuoteLTP
-		params  url.Values
-		qParams quoteParams
+		dst = reflect.ValueOf(quotes)
+		mu  sync.Mutex
 	)
 
-	qParams = quoteParams{
-		Instruments: instruments,
-	}
+	return c.chunkQuotes(ctx, uri, instruments, func(batch []string) error {
+		q := reflect.New(dst.Type())
+		if err := c.getQuotes(ctx, uri, batch, q.Interface()); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for it := q.Elem().MapRange(); it.Next(); {
+			dst.SetMapIndex(it.Key(), it.Value())
+		}
+
+		return nil
+	})
+}
 
-	if params, err = query.Values(qParams); err != nil {
-		return quotes, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
+// Number of quote batches fetched at once when the client has no rate
+// limit for quotes.
+const defaultQuoteWorkers = 4
+
+// quoteWorkers returns the number of quote batches fetched at once, which
+// is the burst of the client's rate limit for quotes, as more requests
+// would only wait for the limiter.
+func (c *Client) quoteWorkers() int {
+	if c.limiter != nil {
+		if b, ok := c.limiter.buckets[EndpointQuote]; ok {
+			return int(b.burst)
+		}
 	}
 
-	err = c.doEnvelope(http.MethodGet, URIGetQuote, params, nil, &quotes)
-	return quotes, err
+	return defaultQuoteWorkers
 }
 
-// GetOHLC gets map of OHLC quotes for given instruments in the format of `exchange:tradingsymbol`.
-func (c *Client) GetOHLC(instruments ...string) (QuoteOHLC, error) {
+// chunkQuotes splits instruments into batches within the limit of the
+// quote endpoint uri and calls fetch for them concurrently, with at most
+// quoteWorkers batches in flight. The requests are paced by the client's
+// rate limiter. If only some batches fail a PartialQuoteError is returned,
+// and if all of them fail the first error.
+func (c *Client) chunkQuotes(ctx context.Context, uri string, instruments []string, fetch func(batch []string) error) error {
+	limit := quoteLimits[uri]
+	if len(instruments) <= limit {
+		return fetch(instruments)
+	}
+
+	var batches [][]string
+	for len(instruments) > 0 {
+		n := limit
+		if len(instruments) < n {
+			n = len(instruments)
+		}
+
+		batches = append(batches, instruments[:n])
+		instruments = instruments[n:]
+	}
+
 	var (
-		err     error
-		quotes  QuoteOHLC
-		params  url.Values
-		qParams quoteParams
+		wg   sync.WaitGroup
+		errs = make([]error, len(batches))
+		next = make(chan int, len(batches))
 	)
 
-	qParams = quoteParams{
-		Instruments: instruments,
+	for i := range batches {
+		next <- i
 	}
+	close(next)
 
-	if params, err = query.Values(qParams); err != nil {
-		return quotes, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
+	workers := c.quoteWorkers()
+	if workers > len(batches) {
+		workers = len(batches)
 	}
 
-	err = c.doEnvelope(http.MethodGet, URIGetQuote, params, nil, &quotes)
-	return quotes, err
-}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = fetch(batches[i])
+			}
+		}()
+	}
+	wg.Wait()
 
-// GetOHLCOMS gets map of OHLC quotes for given instruments in the format of `exchange:tradingsymbol`.
-func (c *Client) GetOHLCOMS(instruments ...string) (QuoteOHLC, error) {
-	var (
-		err     error
-		quotes  QuoteOHLC
-		params  url.Values
-		qParams quoteParams
-	)
+	var failed []QuoteBatchError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, QuoteBatchError{Instruments: batches[i], Err: err})
+		}
+	}
 
-	qParams = quoteParams{
-		Instruments: instruments,
+	switch len(failed) {
+	case 0:
+		return nil
+	case len(batches):
+		return failed[0].Err
 	}
 
-	if params, err = query.Values(qParams); err != nil {
-		return quotes, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
+	return PartialQuoteError{Failed: failed}
+}
+
+// getQuotes requests the quotes of instruments from the quote endpoint uri.
+func (c *Client) getQuotes(ctx context.Context, uri string, instruments []string, obj interface{}) error {
+	params, err := query.Values(quoteParams{Instruments: instruments})
+	if err != nil {
+		return NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	err = c.doEnvelope(http.MethodGet, URIGetQuoteOMS, params, nil, &quotes)
-	return quotes, err
+	return c.doEnvelope(ctx, http.MethodGet, uri, params, nil, obj)
 }
 
 func (c *Client) formatHistoricalData(inp historicalDataReceived) ([]HistoricalData, error) {
@@ -294,6 +473,11 @@ func (c *Client) formatHistoricalData(inp historicalDataReceived) ([]HistoricalD
 
 // GetHistoricalData gets list of historical data.
 func (c *Client) GetHistoricalData(instrumentToken int, interval string, fromDate time.Time, toDate time.Time, continuous bool, OI bool) ([]HistoricalData, error) {
+	return c.GetHistoricalDataWithContext(context.Background(), instrumentToken, interval, fromDate, toDate, continuous, OI)
+}
+
+// GetHistoricalDataWithContext is the context aware variant of GetHistoricalData.
+func (c *Client) GetHistoricalDataWithContext(ctx context.Context, instrumentToken int, interval string, fromDate time.Time, toDate time.Time, continuous bool, OI bool) ([]HistoricalData, error) {
 	var (
 		err       error
 		data      []HistoricalData
@@ -321,49 +505,257 @@ func (c *Client) GetHistoricalData(instrumentToken int, interval string, fromDat
 	}
 
 	var resp historicalDataReceived
-	if err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetHistorical, instrumentToken, interval), params, nil, &resp); err != nil {
+	if err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetHistorical, instrumentToken, interval), params, nil, &resp); err != nil {
 		return data, err
 	}
 
 	return c.formatHistoricalData(resp)
 }
 
-func (c *Client) parseInstruments(data interface{}, url string, params url.Values) error {
-	var (
-		err  error
-		resp HTTPResponse
-	)
-
-	// Get CSV response
-	if resp, err = c.do(http.MethodGet, url, params, nil); err != nil {
+// streamCSV fetches a CSV dump and passes the response body to fn as it
+// arrives.
+func (c *Client) streamCSV(ctx context.Context, uri string, fn func(io.Reader) error) error {
+	ctx, rURL, headers, err := c.prepareRequest(ctx, http.MethodGet, uri, nil)
+	if err != nil {
 		return err
 	}
 
-	// Unmarshal CSV response to instruments
-	if err = gocsv.UnmarshalBytes(resp.Body, data); err != nil {
-		return NewError(GeneralError, fmt.Sprintf("Error parsing csv response: %v", err), nil)
+	err = c.httpClient.DoStream(ctx, http.MethodGet, rURL, nil, headers, fn)
+	if ce, ok := err.(csvError); ok {
+		return withRequest(wrapError(GeneralError, "Error parsing csv response", ce.err), http.MethodGet, rURL)
+	}
+
+	return c.checkTokenError(err)
+}
+
+// csvError marks a failure to decode a CSV row, as opposed to an error
+// returned by the row callback.
+type csvError struct {
+	err error
+}
+
+func (e csvError) Error() string {
+	return e.err.Error()
+}
+
+// decodeInstruments decodes the rows of an instruments CSV from r one at a
+// time, passing each to fn. It stops at the first error returned by fn.
+func decodeInstruments(r io.Reader, fn func(Instrument) error) error {
+	rows := make(chan Instrument, csvRowBuffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- gocsv.UnmarshalToChan(r, rows)
+	}()
+
+	for {
+		select {
+		case inst, ok := <-rows:
+			if !ok {
+				return csvDone(<-done)
+			}
+
+			if err := fn(inst); err != nil {
+				go drainInstruments(rows, done)
+				return err
+			}
+		case err := <-done:
+			if err != nil {
+				return csvError{err}
+			}
+
+			// The decoder closed rows before returning.
+			for inst := range rows {
+				if err := fn(inst); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// decodeMFInstruments is the mutual fund variant of decodeInstruments.
+func decodeMFInstruments(r io.Reader, fn func(MFInstrument) error) error {
+	rows := make(chan MFInstrument, csvRowBuffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- gocsv.UnmarshalToChan(r, rows)
+	}()
+
+	for {
+		select {
+		case inst, ok := <-rows:
+			if !ok {
+				return csvDone(<-done)
+			}
+
+			if err := fn(inst); err != nil {
+				go drainMFInstruments(rows, done)
+				return err
+			}
+		case err := <-done:
+			if err != nil {
+				return csvError{err}
+			}
+
+			for inst := range rows {
+				if err := fn(inst); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// Rows decoded ahead of the row callback.
+const csvRowBuffer = 64
+
+func csvDone(err error) error {
+	if err != nil {
+		return csvError{err}
 	}
 
 	return nil
 }
 
+// drainInstruments lets the decoder run to completion once the caller has
+// stopped reading rows. Closing the response body makes it finish early.
+func drainInstruments(rows chan Instrument, done chan error) {
+	for range rows {
+	}
+	<-done
+}
+
+func drainMFInstruments(rows chan MFInstrument, done chan error) {
+	for range rows {
+	}
+	<-done
+}
+
 // GetInstruments retrives list of instruments.
 func (c *Client) GetInstruments() (Instruments, error) {
+	return c.GetInstrumentsWithContext(context.Background())
+}
+
+// GetInstrumentsWithContext is the context aware variant of GetInstruments.
+func (c *Client) GetInstrumentsWithContext(ctx context.Context) (Instruments, error) {
+	return c.GetInstrumentsMatchingWithContext(ctx, nil)
+}
+
+// GetInstrumentsMatching retrieves the instruments for which keep returns
+// true. Rows are decoded as they arrive, so the instruments which are
+// dropped are never held in memory. A nil keep returns all instruments.
+//
+//	opts, err := kc.GetInstrumentsMatching(func(i kite.Instrument) bool {
+//		return i.Segment == "NFO-OPT"
+//	})
+func (c *Client) GetInstrumentsMatching(keep func(Instrument) bool) (Instruments, error) {
+	return c.GetInstrumentsMatchingWithContext(context.Background(), keep)
+}
+
+// GetInstrumentsMatchingWithContext is the context aware variant of GetInstrumentsMatching.
+func (c *Client) GetInstrumentsMatchingWithContext(ctx context.Context, keep func(Instrument) bool) (Instruments, error) {
 	var instruments Instruments
-	err := c.parseInstruments(&instruments, URIGetInstruments, nil)
+	err := c.StreamInstrumentsWithContext(ctx, collectInstruments(&instruments, keep))
 	return instruments, err
 }
 
+// StreamInstruments decodes the instruments dump as it is downloaded and
+// calls fn with each instrument. Returning an error from fn stops the
+// download and is returned as is.
+func (c *Client) StreamInstruments(fn func(Instrument) error) error {
+	return c.StreamInstrumentsWithContext(context.Background(), fn)
+}
+
+// StreamInstrumentsWithContext is the context aware variant of StreamInstruments.
+func (c *Client) StreamInstrumentsWithContext(ctx context.Context, fn func(Instrument) error) error {
+	return c.streamCSV(ctx, URIGetInstruments, func(r io.Reader) error {
+		return decodeInstruments(r, fn)
+	})
+}
+
 // GetInstrumentsByExchange retrives list of instruments for a given exchange.
 func (c *Client) GetInstrumentsByExchange(exchange string) (Instruments, error) {
+	return c.GetInstrumentsByExchangeWithContext(context.Background(), exchange)
+}
+
+// GetInstrumentsByExchangeWithContext is the context aware variant of GetInstrumentsByExchange.
+func (c *Client) GetInstrumentsByExchangeWithContext(ctx context.Context, exchange string) (Instruments, error) {
+	return c.GetInstrumentsByExchangeMatchingWithContext(ctx, exchange, nil)
+}
+
+// GetInstrumentsByExchangeMatching is the exchange specific variant of
+// GetInstrumentsMatching.
+func (c *Client) GetInstrumentsByExchangeMatching(exchange string, keep func(Instrument) bool) (Instruments, error) {
+	return c.GetInstrumentsByExchangeMatchingWithContext(context.Background(), exchange, keep)
+}
+
+// GetInstrumentsByExchangeMatchingWithContext is the context aware variant of GetInstrumentsByExchangeMatching.
+func (c *Client) GetInstrumentsByExchangeMatchingWithContext(ctx context.Context, exchange string, keep func(Instrument) bool) (Instruments, error) {
 	var instruments Instruments
-	err := c.parseInstruments(&instruments, fmt.Sprintf(URIGetInstrumentsExchange, exchange), nil)
+	err := c.StreamInstrumentsByExchangeWithContext(ctx, exchange, collectInstruments(&instruments, keep))
 	return instruments, err
 }
 
+// StreamInstrumentsByExchange is the exchange specific variant of
+// StreamInstruments.
+func (c *Client) StreamInstrumentsByExchange(exchange string, fn func(Instrument) error) error {
+	return c.StreamInstrumentsByExchangeWithContext(context.Background(), exchange, fn)
+}
+
+// StreamInstrumentsByExchangeWithContext is the context aware variant of StreamInstrumentsByExchange.
+func (c *Client) StreamInstrumentsByExchangeWithContext(ctx context.Context, exchange string, fn func(Instrument) error) error {
+	return c.streamCSV(ctx, fmt.Sprintf(URIGetInstrumentsExchange, exchange), func(r io.Reader) error {
+		return decodeInstruments(r, fn)
+	})
+}
+
+func collectInstruments(out *Instruments, keep func(Instrument) bool) func(Instrument) error {
+	return func(inst Instrument) error {
+		if keep == nil || keep(inst) {
+			*out = append(*out, inst)
+		}
+		return nil
+	}
+}
+
 // GetMFInstruments retrives list of mutualfund instruments.
 func (c *Client) GetMFInstruments() (MFInstruments, error) {
+	return c.GetMFInstrumentsWithContext(context.Background())
+}
+
+// GetMFInstrumentsWithContext is the context aware variant of GetMFInstruments.
+func (c *Client) GetMFInstrumentsWithContext(ctx context.Context) (MFInstruments, error) {
+	return c.GetMFInstrumentsMatchingWithContext(ctx, nil)
+}
+
+// GetMFInstrumentsMatching is the mutual fund variant of
+// GetInstrumentsMatching.
+func (c *Client) GetMFInstrumentsMatching(keep func(MFInstrument) bool) (MFInstruments, error) {
+	return c.GetMFInstrumentsMatchingWithContext(context.Background(), keep)
+}
+
+// GetMFInstrumentsMatchingWithContext is the context aware variant of GetMFInstrumentsMatching.
+func (c *Client) GetMFInstrumentsMatchingWithContext(ctx context.Context, keep func(MFInstrument) bool) (MFInstruments, error) {
 	var instruments MFInstruments
-	err := c.parseInstruments(&instruments, URIGetMFInstruments, nil)
+	err := c.StreamMFInstrumentsWithContext(ctx, func(inst MFInstrument) error {
+		if keep == nil || keep(inst) {
+			instruments = append(instruments, inst)
+		}
+		return nil
+	})
 	return instruments, err
 }
+
+// StreamMFInstruments is the mutual fund variant of StreamInstruments.
+func (c *Client) StreamMFInstruments(fn func(MFInstrument) error) error {
+	return c.StreamMFInstrumentsWithContext(context.Background(), fn)
+}
+
+// StreamMFInstrumentsWithContext is the context aware variant of StreamMFInstruments.
+func (c *Client) StreamMFInstrumentsWithContext(ctx context.Context, fn func(MFInstrument) error) error {
+	return c.streamCSV(ctx, URIGetMFInstruments, func(r io.Reader) error {
+		return decodeMFInstruments(r, fn)
+	})
+}
@@ -1,15 +1,27 @@
-package kite
+package kite_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/santoshanand/at-kite/kitetest"
 	"github.com/stretchr/testify/require"
 )
 
+// The seeded candles span 09:15 to 09:20 IST on 14 Jul 2023.
+var (
+	histFrom = time.Date(2023, 7, 14, 9, 15, 0, 0, time.FixedZone("IST", 5*60*60+30*60))
+	histTo   = histFrom.Add(time.Hour)
+)
+
 func TestGetQuote(t *testing.T) {
 	t.Parallel()
-	marketQuote, err := getKite().GetQuote()
+	marketQuote, err := getKite().GetQuote("NSE:INFY")
 	if err != nil {
 		t.Errorf("Error while fetching MF orders. %v", err)
 	}
@@ -25,7 +37,7 @@ func TestGetQuote(t *testing.T) {
 
 func TestGetLTP(t *testing.T) {
 	t.Parallel()
-	marketLTP, err := getKite().GetLTP()
+	marketLTP, err := getKite().GetLTP("NSE:INFY")
 	if err != nil {
 		t.Errorf("Error while fetching MF orders. %v", err)
 	}
@@ -41,7 +53,7 @@ func TestGetLTP(t *testing.T) {
 
 func TestGetHistoricalData(t *testing.T) {
 	t.Parallel()
-	marketHistorical, err := getKite().GetHistoricalData(123, "myinterval", time.Unix(0, 0), time.Unix(1, 0), true, false)
+	marketHistorical, err := getKite().GetHistoricalData(123, "myinterval", histFrom, histTo, true, false)
 	if err != nil {
 		t.Errorf("Error while fetching MF orders. %v", err)
 	}
@@ -56,7 +68,7 @@ func TestGetHistoricalData(t *testing.T) {
 
 func TestGetHistoricalDataWithOI(t *testing.T) {
 	t.Parallel()
-	marketHistorical, err := getKite().GetHistoricalData(456, "myinterval", time.Unix(0, 0), time.Unix(1, 0), true, true)
+	marketHistorical, err := getKite().GetHistoricalData(456, "myinterval", histFrom, histTo, true, true)
 	require.Nil(t, err)
 	require.Equal(t, 6, len(marketHistorical))
 
@@ -68,7 +80,7 @@ func TestGetHistoricalDataWithOI(t *testing.T) {
 
 func TestGetOHLC(t *testing.T) {
 	t.Parallel()
-	marketOHLC, err := getKite().GetOHLC()
+	marketOHLC, err := getKite().GetOHLC("NSE:INFY")
 	if err != nil {
 		t.Errorf("Error while fetching MF orders. %v", err)
 	}
@@ -129,3 +141,159 @@ func TestGetMFInstruments(t *testing.T) {
 		}
 	}
 }
+
+func TestStreamInstruments(t *testing.T) {
+	t.Parallel()
+	kc := getKite()
+
+	var tokens []int
+	err := kc.StreamInstruments(func(i kite.Instrument) error {
+		tokens = append(tokens, i.InstrumentToken)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []int{408065, 12074242}, tokens)
+
+	// An error from the callback stops the stream.
+	errStop := errors.New("stop")
+	calls := 0
+	err = kc.StreamInstrumentsWithContext(context.Background(), func(i kite.Instrument) error {
+		calls++
+		return errStop
+	})
+	require.Equal(t, errStop, err)
+	require.Equal(t, 1, calls)
+
+	opts, err := kc.GetInstrumentsMatching(func(i kite.Instrument) bool {
+		return i.Segment == "NFO-OPT"
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(opts))
+	require.Equal(t, "NIFTY18JUN10500CE", opts[0].Tradingsymbol)
+
+	nse, err := kc.GetInstrumentsByExchangeMatching(kite.ExchangeNSE, kite.InstrumentFilter{Name: "INFOSYS"}.Match)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(nse))
+
+	mf, err := kc.GetMFInstrumentsMatching(func(i kite.MFInstrument) bool {
+		return i.PurchaseAllowed
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(mf))
+}
+
+func TestStreamInstrumentsErrors(t *testing.T) {
+	t.Parallel()
+	s := kitetest.NewServer()
+	defer s.Close()
+	kc := s.NewClient()
+	kc.SetRetryPolicy(kite.NoRetryPolicy())
+
+	s.Inject(kitetest.Fault{Path: kite.URIGetInstruments, Times: 1, Status: http.StatusForbidden, ErrorType: kite.PermissionError, Message: "Insufficient permission"})
+	_, err := kc.GetInstruments()
+	require.True(t, errors.Is(err, kite.ErrPermission))
+	require.Equal(t, http.StatusForbidden, err.(kite.Error).Status)
+
+	s.Inject(kitetest.Fault{Path: kite.URIGetInstruments, Times: 1, Body: "instrument_token,tradingsymbol\nabc,INFY\n"})
+	_, err = kc.GetInstruments()
+	require.Error(t, err)
+	require.Equal(t, kite.GeneralError, err.(kite.Error).ErrorType)
+	require.Equal(t, kite.URIGetInstruments, err.(kite.Error).Path)
+}
+
+func TestGetQuoteChunks(t *testing.T) {
+	t.Parallel()
+	s := kitetest.NewServer()
+	defer s.Close()
+
+	var symbols []string
+	quotes := kite.Quote{}
+	for i := 0; i < 1201; i++ {
+		sym := fmt.Sprintf("NFO:OPT%d", i)
+		symbols = append(symbols, sym)
+		quotes[sym] = kite.QuoteData{InstrumentToken: i, LastPrice: float64(i)}
+	}
+	s.SetQuotes(quotes)
+
+	kc := s.NewClient()
+	kc.SetRetryPolicy(kite.NoRetryPolicy())
+
+	all, err := kc.GetQuote(symbols...)
+	require.Nil(t, err)
+	require.Equal(t, 1201, len(all))
+	require.Equal(t, 1200.0, all["NFO:OPT1200"].LastPrice)
+	require.Equal(t, 3, len(s.Requests()))
+
+	ltp, err := kc.GetLTP(symbols...)
+	require.Nil(t, err)
+	require.Equal(t, 1201, len(ltp))
+
+	// One of the batches fails.
+	s.Inject(kitetest.Fault{Path: kite.URIGetQuote, Times: 1, Status: http.StatusInternalServerError, ErrorType: kite.GeneralError, Message: "Something went wrong"})
+	all, err = kc.GetQuote(symbols...)
+	require.Error(t, err)
+
+	var partial kite.PartialQuoteError
+	require.True(t, errors.As(err, &partial))
+	require.Equal(t, 1, len(partial.Failed))
+	require.True(t, errors.Is(err, kite.ErrGeneral))
+	require.Equal(t, 1201-len(partial.Instruments()), len(all))
+	for _, sym := range partial.Instruments() {
+		_, ok := all[sym]
+		require.False(t, ok)
+	}
+
+	// All the batches fail.
+	s.Inject(kitetest.Fault{Path: kite.URIGetQuote, Times: 3, Status: http.StatusForbidden, ErrorType: kite.PermissionError, Message: "Insufficient permission"})
+	_, err = kc.GetQuote(symbols...)
+	require.Equal(t, kite.PermissionError, err.(kite.Error).ErrorType)
+}
+
+func TestQuoteEndpoints(t *testing.T) {
+	t.Parallel()
+	s := kitetest.NewServer()
+	defer s.Close()
+
+	s.SetQuotes(kite.Quote{
+		"NSE:INFY": {InstrumentToken: 408065, LastPrice: 1450, Volume: 100, OHLC: kite.OHLC{Open: 1440}},
+		"NSE:TCS":  {InstrumentToken: 2953217, LastPrice: 3400},
+	})
+	kc := s.NewClient()
+
+	ltp, err := kc.GetLTP("NSE:INFY", "NSE:TCS")
+	require.Nil(t, err)
+	require.Equal(t, kite.LTPData{InstrumentToken: 408065, LastPrice: 1450}, ltp["NSE:INFY"])
+
+	ohlc, err := kc.GetOHLC("NSE:INFY")
+	require.Nil(t, err)
+	require.Equal(t, kite.OHLCData{InstrumentToken: 408065, LastPrice: 1450, OHLC: kite.OHLC{Open: 1440}}, ohlc["NSE:INFY"])
+
+	reqs := s.Requests()
+	require.Equal(t, kite.URIGetLTP, reqs[0].Path)
+	require.Equal(t, kite.URIGetOHLC, reqs[1].Path)
+
+	// Instruments can be requested and looked up by token.
+	quotes, err := kc.GetQuote("408065", "NSE:TCS")
+	require.Nil(t, err)
+	require.Equal(t, 1450.0, quotes["408065"].LastPrice)
+
+	q, ok := quotes.ByToken(2953217)
+	require.True(t, ok)
+	require.Equal(t, 3400.0, q.LastPrice)
+	_, ok = quotes.ByToken(1)
+	require.False(t, ok)
+
+	l, ok := ltp.ByToken(2953217)
+	require.True(t, ok)
+	require.Equal(t, 3400.0, l.LastPrice)
+
+	o, ok := ohlc.ByToken(408065)
+	require.True(t, ok)
+	require.Equal(t, 1440.0, o.OHLC.Open)
+
+	byToken := quotes.IndexByToken()
+	require.Equal(t, 2, len(byToken))
+	require.Equal(t, 3400.0, byToken[2953217].LastPrice)
+	require.Equal(t, 1440.0, ohlc.IndexByToken()[408065].OHLC.Open)
+	require.Equal(t, 3400.0, ltp.IndexByToken()[2953217].LastPrice)
+}
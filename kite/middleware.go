@@ -0,0 +1,141 @@
+package kite
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Middleware hooks into every request made by the HTTP client. Any of the
+// hooks can be left nil. Hooks are invoked for each attempt, so a retried
+// request goes through them more than once.
+type Middleware struct {
+	// BeforeRequest is called before the request is sent and may modify it,
+	// for instance to add headers. Returning an error aborts the request.
+	BeforeRequest func(req *http.Request) error
+
+	// AfterResponse is called once the response has been read. body is nil
+	// for responses which were streamed to the caller.
+	AfterResponse func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration)
+
+	// OnError is called when the request fails without a response.
+	OnError func(req *http.Request, err error, elapsed time.Duration)
+}
+
+// Headers which carry credentials and are masked by RedactHeaders.
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// RedactHeaders returns a copy of h with credentials masked. The auth scheme
+// of the Authorization header (`enctoken`, `token`) is preserved.
+func RedactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range redactedHeaders {
+		vals := out.Values(k)
+		if len(vals) == 0 {
+			continue
+		}
+
+		masked := make([]string, len(vals))
+		for i, v := range vals {
+			if p := strings.IndexByte(v, ' '); p > 0 && k == "Authorization" {
+				masked[i] = v[:p] + " [REDACTED]"
+			} else {
+				masked[i] = "[REDACTED]"
+			}
+		}
+		out[http.CanonicalHeaderKey(k)] = masked
+	}
+
+	return out
+}
+
+const redacted = "[REDACTED]"
+
+// Query and form params which carry credentials and are masked by
+// RedactURL.
+var redactedParams = []string{"access_token", "refresh_token", "request_token", "checksum"}
+
+// RedactURL returns the path and query of u with credentials masked, as
+// sent by InvalidateAccessToken and InvalidateRefreshToken.
+func RedactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	q := u.Query()
+	for _, k := range redactedParams {
+		if vals, ok := q[k]; ok {
+			for i := range vals {
+				vals[i] = redacted
+			}
+		}
+	}
+
+	return u.Path + "?" + strings.Replace(q.Encode(), url.QueryEscape(redacted), redacted, -1)
+}
+
+// redactRawURL is RedactURL for a URL which hasn't been parsed.
+func redactRawURL(rURL string) string {
+	u, err := url.Parse(rURL)
+	if err != nil {
+		return redacted
+	}
+
+	return RedactURL(u)
+}
+
+// LoggingMiddleware logs every request with its status, timing and
+// redacted URL and headers to l. This is what SetDebug(true) enables.
+func LoggingMiddleware(l *log.Logger) Middleware {
+	return Middleware{
+		AfterResponse: func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration) {
+			l.Printf("%s %s -- %d %v %v", req.Method, RedactURL(req.URL), resp.StatusCode, elapsed, RedactHeaders(req.Header))
+		},
+		OnError: func(req *http.Request, err error, elapsed time.Duration) {
+			l.Printf("%s %s -- error %v %v %v", req.Method, RedactURL(req.URL), err, elapsed, RedactHeaders(req.Header))
+		},
+	}
+}
+
+// HeaderMiddleware sets the given headers on every request.
+func HeaderMiddleware(headers http.Header) Middleware {
+	return Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			for k, v := range headers {
+				req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+			}
+			return nil
+		},
+	}
+}
+
+// RequestIDMiddleware sets a unique request ID on every request under the
+// given header, unless the request already carries one. If gen is nil a
+// random 16 byte hex ID is used.
+func RequestIDMiddleware(header string, gen func() string) Middleware {
+	if gen == nil {
+		gen = newRequestID
+	}
+
+	return Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			if req.Header.Get(header) == "" {
+				req.Header.Set(header, gen())
+			}
+			return nil
+		},
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,150 @@
+package kite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	t.Parallel()
+	h := http.Header{}
+	h.Set("Authorization", "enctoken secret")
+	h.Set("X-Kite-Version", "3")
+
+	r := RedactHeaders(h)
+	require.Equal(t, "enctoken [REDACTED]", r.Get("Authorization"))
+	require.Equal(t, "3", r.Get("X-Kite-Version"))
+	require.Equal(t, "enctoken secret", h.Get("Authorization"))
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	t.Parallel()
+	var gotHeaders http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer ts.Close()
+
+	var calls []string
+	kc := New("secret")
+	kc.SetBaseURI(ts.URL)
+	kc.Use(
+		HeaderMiddleware(http.Header{"X-Custom": []string{"1"}}),
+		RequestIDMiddleware("X-Request-Id", func() string { return "req-1" }),
+		Middleware{
+			BeforeRequest: func(req *http.Request) error {
+				calls = append(calls, "before")
+				return nil
+			},
+			AfterResponse: func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration) {
+				calls = append(calls, "after")
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.Contains(t, string(body), "success")
+			},
+		},
+	)
+
+	_, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, []string{"before", "after"}, calls)
+	require.Equal(t, "1", gotHeaders.Get("X-Custom"))
+	require.Equal(t, "req-1", gotHeaders.Get("X-Request-Id"))
+}
+
+func TestMiddlewareAbort(t *testing.T) {
+	t.Parallel()
+	h := NewHTTPClient(nil, nil, false)
+	h.GetClient().Use(Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			return errors.New("blocked")
+		},
+	})
+
+	_, err := h.Do(context.Background(), http.MethodGet, "http://127.0.0.1:0/", nil, nil)
+	require.Error(t, err)
+	require.Equal(t, InputError, err.(Error).ErrorType)
+}
+
+func TestDebugLogging(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	h := NewHTTPClient(nil, log.New(&buf, "", 0), true)
+	headers := http.Header{}
+	headers.Set("Authorization", "enctoken secret")
+
+	_, err := h.Do(context.Background(), http.MethodGet, ts.URL+URIGetOrders, nil, headers)
+	require.Nil(t, err)
+
+	out := buf.String()
+	require.True(t, strings.Contains(out, "GET /orders -- 200"), out)
+	require.True(t, strings.Contains(out, "enctoken [REDACTED]"), out)
+	require.False(t, strings.Contains(out, "secret"), out)
+}
+
+func TestLoggingRedactsCredentials(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":true}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c := New("live-access-token")
+	c.SetBaseURI(ts.URL)
+	c.Use(LoggingMiddleware(log.New(&buf, "", 0)))
+
+	_, err := c.InvalidateAccessToken()
+	require.Nil(t, err)
+	_, err = c.InvalidateRefreshToken("live-refresh-token")
+	require.Nil(t, err)
+
+	out := buf.String()
+	require.True(t, strings.Contains(out, "DELETE /session/token?access_token=[REDACTED]"), out)
+	require.True(t, strings.Contains(out, "refresh_token=[REDACTED]"), out)
+	require.False(t, strings.Contains(out, "live-access-token"), out)
+	require.False(t, strings.Contains(out, "live-refresh-token"), out)
+
+	u, _ := url.Parse("/orders")
+	require.Equal(t, "/orders", RedactURL(u))
+}
+
+func TestHTTPClientLogsRedactCredentials(t *testing.T) {
+	t.Parallel()
+	ts, _ := flakyServer(1, http.StatusServiceUnavailable)
+	defer ts.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	var buf bytes.Buffer
+	h := NewHTTPClient(nil, log.New(&buf, "", 0), true).GetClient()
+	h.SetRetryPolicy(testRetryPolicy())
+	params := url.Values{"access_token": {"live-access-token"}}
+
+	_, err := h.Do(context.Background(), http.MethodGet, ts.URL+URIGetOrders+"?access_token=live-access-token", params, nil)
+	require.Nil(t, err)
+
+	h.SetRetryPolicy(NoRetryPolicy())
+	_, err = h.Do(context.Background(), http.MethodDelete, closed.URL+URIUserSessionInvalidate, params, nil)
+	require.Error(t, err)
+
+	out := buf.String()
+	require.True(t, strings.Contains(out, "Retrying GET /orders?access_token=[REDACTED]"), out)
+	require.True(t, strings.Contains(out, "Request failed: "), out)
+	require.False(t, strings.Contains(out, "live-access-token"), out)
+}
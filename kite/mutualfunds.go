@@ -1,6 +1,7 @@
 package kite
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -144,20 +145,35 @@ type MFSIPModifyParams struct {
 
 // GetMFOrders gets list of mutualfund orders.
 func (c *Client) GetMFOrders() (MFOrders, error) {
+	return c.GetMFOrdersWithContext(context.Background())
+}
+
+// GetMFOrdersWithContext is the context aware variant of GetMFOrders.
+func (c *Client) GetMFOrdersWithContext(ctx context.Context) (MFOrders, error) {
 	var orders MFOrders
-	err := c.doEnvelope(http.MethodGet, URIGetMFOrders, nil, nil, &orders)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetMFOrders, nil, nil, &orders)
 	return orders, err
 }
 
 // GetMFOrderInfo get individual mutualfund order info.
 func (c *Client) GetMFOrderInfo(OrderID string) (MFOrder, error) {
+	return c.GetMFOrderInfoWithContext(context.Background(), OrderID)
+}
+
+// GetMFOrderInfoWithContext is the context aware variant of GetMFOrderInfo.
+func (c *Client) GetMFOrderInfoWithContext(ctx context.Context, OrderID string) (MFOrder, error) {
 	var orderInfo MFOrder
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetMFOrderInfo, OrderID), nil, nil, &orderInfo)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetMFOrderInfo, OrderID), nil, nil, &orderInfo)
 	return orderInfo, err
 }
 
 // GetMFOrdersByDate gets list of mutualfund orders for a custom date range.
 func (c *Client) GetMFOrdersByDate(fromDate, toDate string) (MFOrders, error) {
+	return c.GetMFOrdersByDateWithContext(context.Background(), fromDate, toDate)
+}
+
+// GetMFOrdersByDateWithContext is the context aware variant of GetMFOrdersByDate.
+func (c *Client) GetMFOrdersByDateWithContext(ctx context.Context, fromDate, toDate string) (MFOrders, error) {
 	var (
 		orders MFOrders
 	)
@@ -166,12 +182,17 @@ func (c *Client) GetMFOrdersByDate(fromDate, toDate string) (MFOrders, error) {
 	params.Add("from", fromDate)
 	params.Add("to", toDate)
 
-	err := c.doEnvelope(http.MethodGet, URIGetMFOrders, params, nil, &orders)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetMFOrders, params, nil, &orders)
 	return orders, err
 }
 
 // PlaceMFOrder places an mutualfund order.
 func (c *Client) PlaceMFOrder(orderParams MFOrderParams) (MFOrderResponse, error) {
+	return c.PlaceMFOrderWithContext(context.Background(), orderParams)
+}
+
+// PlaceMFOrderWithContext is the context aware variant of PlaceMFOrder.
+func (c *Client) PlaceMFOrderWithContext(ctx context.Context, orderParams MFOrderParams) (MFOrderResponse, error) {
 	var (
 		orderResponse MFOrderResponse
 		params        url.Values
@@ -182,26 +203,41 @@ func (c *Client) PlaceMFOrder(orderParams MFOrderParams) (MFOrderResponse, error
 		return orderResponse, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	err = c.doEnvelope(http.MethodPost, URIPlaceMFOrder, params, nil, &orderResponse)
+	err = c.doEnvelope(ctx, http.MethodPost, URIPlaceMFOrder, params, nil, &orderResponse)
 	return orderResponse, err
 }
 
 // GetMFSIPs gets list of mutualfund SIPs.
 func (c *Client) GetMFSIPs() (MFSIPs, error) {
+	return c.GetMFSIPsWithContext(context.Background())
+}
+
+// GetMFSIPsWithContext is the context aware variant of GetMFSIPs.
+func (c *Client) GetMFSIPsWithContext(ctx context.Context) (MFSIPs, error) {
 	var sips MFSIPs
-	err := c.doEnvelope(http.MethodGet, URIGetMFSIPs, nil, nil, &sips)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetMFSIPs, nil, nil, &sips)
 	return sips, err
 }
 
 // GetMFSIPInfo get individual SIP info.
 func (c *Client) GetMFSIPInfo(sipID string) (MFSIP, error) {
+	return c.GetMFSIPInfoWithContext(context.Background(), sipID)
+}
+
+// GetMFSIPInfoWithContext is the context aware variant of GetMFSIPInfo.
+func (c *Client) GetMFSIPInfoWithContext(ctx context.Context, sipID string) (MFSIP, error) {
 	var sip MFSIP
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetMFSIPInfo, sipID), nil, nil, &sip)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetMFSIPInfo, sipID), nil, nil, &sip)
 	return sip, err
 }
 
 // PlaceMFSIP places an mutualfund SIP order.
 func (c *Client) PlaceMFSIP(sipParams MFSIPParams) (MFSIPResponse, error) {
+	return c.PlaceMFSIPWithContext(context.Background(), sipParams)
+}
+
+// PlaceMFSIPWithContext is the context aware variant of PlaceMFSIP.
+func (c *Client) PlaceMFSIPWithContext(ctx context.Context, sipParams MFSIPParams) (MFSIPResponse, error) {
 	var (
 		sipResponse MFSIPResponse
 		params      url.Values
@@ -212,12 +248,17 @@ func (c *Client) PlaceMFSIP(sipParams MFSIPParams) (MFSIPResponse, error) {
 		return sipResponse, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	err = c.doEnvelope(http.MethodPost, URIPlaceMFSIP, params, nil, &sipResponse)
+	err = c.doEnvelope(ctx, http.MethodPost, URIPlaceMFSIP, params, nil, &sipResponse)
 	return sipResponse, err
 }
 
 // ModifyMFSIP modifies an mutualfund SIP.
 func (c *Client) ModifyMFSIP(sipID string, sipParams MFSIPModifyParams) (MFSIPResponse, error) {
+	return c.ModifyMFSIPWithContext(context.Background(), sipID, sipParams)
+}
+
+// ModifyMFSIPWithContext is the context aware variant of ModifyMFSIP.
+func (c *Client) ModifyMFSIPWithContext(ctx context.Context, sipID string, sipParams MFSIPModifyParams) (MFSIPResponse, error) {
 	var (
 		sipResponse MFSIPResponse
 		params      url.Values
@@ -228,44 +269,69 @@ func (c *Client) ModifyMFSIP(sipID string, sipParams MFSIPModifyParams) (MFSIPRe
 		return sipResponse, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	err = c.doEnvelope(http.MethodPut, fmt.Sprintf(URIModifyMFSIP, sipID), params, nil, &sipResponse)
+	err = c.doEnvelope(ctx, http.MethodPut, fmt.Sprintf(URIModifyMFSIP, sipID), params, nil, &sipResponse)
 	return sipResponse, err
 }
 
 // CancelMFSIP cancels an mutualfund SIP.
 func (c *Client) CancelMFSIP(sipID string) (MFSIPResponse, error) {
+	return c.CancelMFSIPWithContext(context.Background(), sipID)
+}
+
+// CancelMFSIPWithContext is the context aware variant of CancelMFSIP.
+func (c *Client) CancelMFSIPWithContext(ctx context.Context, sipID string) (MFSIPResponse, error) {
 	var (
 		sipResponse MFSIPResponse
 	)
 
-	err := c.doEnvelope(http.MethodDelete, fmt.Sprintf(URICancelMFSIP, sipID), nil, nil, &sipResponse)
+	err := c.doEnvelope(ctx, http.MethodDelete, fmt.Sprintf(URICancelMFSIP, sipID), nil, nil, &sipResponse)
 	return sipResponse, err
 }
 
 // CancelMFOrder cancels an mutualfund order.
 func (c *Client) CancelMFOrder(orderID string) (MFOrderResponse, error) {
+	return c.CancelMFOrderWithContext(context.Background(), orderID)
+}
+
+// CancelMFOrderWithContext is the context aware variant of CancelMFOrder.
+func (c *Client) CancelMFOrderWithContext(ctx context.Context, orderID string) (MFOrderResponse, error) {
 	var orderResponse MFOrderResponse
-	err := c.doEnvelope(http.MethodDelete, fmt.Sprintf(URICancelMFOrder, orderID), nil, nil, &orderResponse)
+	err := c.doEnvelope(ctx, http.MethodDelete, fmt.Sprintf(URICancelMFOrder, orderID), nil, nil, &orderResponse)
 	return orderResponse, err
 }
 
 // GetMFHoldings gets list of user mutualfund holdings.
 func (c *Client) GetMFHoldings() (MFHoldings, error) {
+	return c.GetMFHoldingsWithContext(context.Background())
+}
+
+// GetMFHoldingsWithContext is the context aware variant of GetMFHoldings.
+func (c *Client) GetMFHoldingsWithContext(ctx context.Context) (MFHoldings, error) {
 	var holdings MFHoldings
-	err := c.doEnvelope(http.MethodGet, URIGetMFHoldings, nil, nil, &holdings)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetMFHoldings, nil, nil, &holdings)
 	return holdings, err
 }
 
 // GetMFHoldingInfo get individual Holding info.
 func (c *Client) GetMFHoldingInfo(isin string) (MFHoldingBreakdown, error) {
+	return c.GetMFHoldingInfoWithContext(context.Background(), isin)
+}
+
+// GetMFHoldingInfoWithContext is the context aware variant of GetMFHoldingInfo.
+func (c *Client) GetMFHoldingInfoWithContext(ctx context.Context, isin string) (MFHoldingBreakdown, error) {
 	var holdingBreakdown MFHoldingBreakdown
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetMFHoldingInfo, isin), nil, nil, &holdingBreakdown)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetMFHoldingInfo, isin), nil, nil, &holdingBreakdown)
 	return holdingBreakdown, err
 }
 
 // GetMFAllottedISINs gets list of user mutualfund holdings.
 func (c *Client) GetMFAllottedISINs() (MFAllottedISINs, error) {
+	return c.GetMFAllottedISINsWithContext(context.Background())
+}
+
+// GetMFAllottedISINsWithContext is the context aware variant of GetMFAllottedISINs.
+func (c *Client) GetMFAllottedISINsWithContext(ctx context.Context) (MFAllottedISINs, error) {
 	var isins MFAllottedISINs
-	err := c.doEnvelope(http.MethodGet, URIGetAllotedISINs, nil, nil, &isins)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetAllotedISINs, nil, nil, &isins)
 	return isins, err
 }
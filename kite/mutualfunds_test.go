@@ -1,7 +1,9 @@
-package kite
+package kite_test
 
 import (
 	"testing"
+
+	"github.com/santoshanand/at-kite/kite"
 )
 
 func TestGetMFOrders(t *testing.T) {
@@ -30,7 +32,7 @@ func TestGetMFOrderInfo(t *testing.T) {
 
 func TestPlaceMFOrder(t *testing.T) {
 	t.Parallel()
-	params := MFOrderParams{
+	params := kite.MFOrderParams{
 		Tradingsymbol:   "test",
 		TransactionType: "test",
 		Quantity:        100,
@@ -69,7 +71,7 @@ func TestGetMFSIPInfo(t *testing.T) {
 
 func TestPlaceMFSIP(t *testing.T) {
 	t.Parallel()
-	params := MFSIPParams{
+	params := kite.MFSIPParams{
 		Tradingsymbol: "test",
 		Amount:        100,
 		Instalments:   100,
@@ -89,7 +91,7 @@ func TestPlaceMFSIP(t *testing.T) {
 
 func TestModifyMFSIP(t *testing.T) {
 	t.Parallel()
-	params := MFSIPModifyParams{
+	params := kite.MFSIPModifyParams{
 		Amount:        100,
 		Frequency:     "test",
 		InstalmentDay: 100,
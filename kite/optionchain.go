@@ -0,0 +1,231 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// OptionLeg is the call or the put of an option chain row, with its quote.
+type OptionLeg struct {
+	Instrument Instrument
+	QuoteData
+}
+
+// OptionChainRow holds the call and the put of a strike. Either may be nil
+// if only one side is listed.
+type OptionChainRow struct {
+	Strike float64
+	Call   *OptionLeg
+	Put    *OptionLeg
+}
+
+// OptionChain is the chain of options of an underlying for an expiry, with
+// rows in ascending order of strike.
+type OptionChain struct {
+	Name            string
+	Expiry          time.Time
+	UnderlyingPrice float64
+	Rows            []OptionChainRow
+}
+
+// NewOptionChain builds an option chain from the option instruments of an
+// underlying for a single expiry and their quotes, keyed by
+// `exchange:tradingsymbol`. Instruments which aren't options are skipped.
+func NewOptionChain(instruments Instruments, quotes Quote, underlyingPrice float64) *OptionChain {
+	oc := &OptionChain{UnderlyingPrice: underlyingPrice}
+
+	byStrike := make(map[float64]int)
+	for _, inst := range instruments {
+		if inst.InstrumentType != InstrumentTypeCE && inst.InstrumentType != InstrumentTypePE {
+			continue
+		}
+
+		if oc.Name == "" {
+			oc.Name = inst.Name
+			oc.Expiry = inst.Expiry.Time
+		}
+
+		leg := &OptionLeg{
+			Instrument: inst,
+			QuoteData:  quotes[inst.Exchange+":"+inst.Tradingsymbol],
+		}
+
+		i, ok := byStrike[inst.StrikePrice]
+		if !ok {
+			i = len(oc.Rows)
+			byStrike[inst.StrikePrice] = i
+			oc.Rows = append(oc.Rows, OptionChainRow{Strike: inst.StrikePrice})
+		}
+
+		if inst.InstrumentType == InstrumentTypeCE {
+			oc.Rows[i].Call = leg
+		} else {
+			oc.Rows[i].Put = leg
+		}
+	}
+
+	sort.Slice(oc.Rows, func(i, j int) bool {
+		return oc.Rows[i].Strike < oc.Rows[j].Strike
+	})
+
+	return oc
+}
+
+// ATMIndex returns the index of the row whose strike is closest to the
+// underlying price, or -1 if the chain is empty or the underlying price
+// isn't known. Of two equally close strikes the lower one is returned.
+func (oc *OptionChain) ATMIndex() int {
+	if len(oc.Rows) == 0 || oc.UnderlyingPrice <= 0 {
+		return -1
+	}
+
+	atm := 0
+	for i, r := range oc.Rows {
+		if math.Abs(r.Strike-oc.UnderlyingPrice) < math.Abs(oc.Rows[atm].Strike-oc.UnderlyingPrice) {
+			atm = i
+		}
+	}
+
+	return atm
+}
+
+// ATM returns the at the money row. See ATMIndex.
+func (oc *OptionChain) ATM() (OptionChainRow, bool) {
+	i := oc.ATMIndex()
+	if i < 0 {
+		return OptionChainRow{}, false
+	}
+
+	return oc.Rows[i], true
+}
+
+// PCR returns the put call ratio of the chain's open interest. It is 0 if
+// there is no call open interest.
+func (oc *OptionChain) PCR() float64 {
+	var calls, puts float64
+	for _, r := range oc.Rows {
+		if r.Call != nil {
+			calls += r.Call.OI
+		}
+
+		if r.Put != nil {
+			puts += r.Put.OI
+		}
+	}
+
+	if calls == 0 {
+		return 0
+	}
+
+	return puts / calls
+}
+
+// MaxPain returns the strike at which option buyers would lose the most,
+// that is the expiry price at which the intrinsic value of all the open
+// calls and puts is the least. It is 0 for an empty chain.
+func (oc *OptionChain) MaxPain() float64 {
+	var (
+		maxPain float64
+		least   = math.Inf(1)
+	)
+
+	for _, expiry := range oc.Rows {
+		var value float64
+		for _, r := range oc.Rows {
+			if r.Call != nil && expiry.Strike > r.Strike {
+				value += r.Call.OI * (expiry.Strike - r.Strike)
+			}
+
+			if r.Put != nil && expiry.Strike < r.Strike {
+				value += r.Put.OI * (r.Strike - expiry.Strike)
+			}
+		}
+
+		if value < least {
+			least = value
+			maxPain = expiry.Strike
+		}
+	}
+
+	return maxPain
+}
+
+// OptionExpiries returns the expiries of the options of the underlying name
+// listed on exchange, in ascending order.
+func (m *InstrumentMaster) OptionExpiries(exchange, name string) []time.Time {
+	var expiries []time.Time
+	seen := make(map[string]bool)
+	for _, inst := range m.Query(InstrumentFilter{Exchange: exchange, Name: name, InstrumentTypes: []string{InstrumentTypeCE, InstrumentTypePE}}) {
+		day := inst.Expiry.In(istLocation()).Format(instrumentsCacheLayout)
+		if inst.Expiry.IsZero() || seen[day] {
+			continue
+		}
+
+		seen[day] = true
+		expiries = append(expiries, inst.Expiry.Time)
+	}
+
+	sort.Slice(expiries, func(i, j int) bool {
+		return expiries[i].Before(expiries[j])
+	})
+
+	return expiries
+}
+
+// GetOptionChain fetches the option chain of the underlying name listed on
+// exchange for the given expiry day. Instruments are looked up in im, which
+// must be loaded. underlying is the `exchange:tradingsymbol` quoted for the
+// ATM strike, such as `NSE:NIFTY 50`, and may be empty. If neither options
+// nor an underlying are to be quoted, an empty chain is returned without a
+// request.
+//
+// Long chains are quoted in several batches. If some of them fail, the
+// chain is returned along with a PartialQuoteError, and the legs of the
+// instruments it lists have no quote.
+//
+//	expiries := im.OptionExpiries(kite.ExchangeNFO, "NIFTY")
+//	oc, err := kc.GetOptionChain(im, kite.ExchangeNFO, "NIFTY", expiries[0], "NSE:NIFTY 50")
+func (c *Client) GetOptionChain(im *InstrumentMaster, exchange, name string, expiry time.Time, underlying string) (*OptionChain, error) {
+	return c.GetOptionChainWithContext(context.Background(), im, exchange, name, expiry, underlying)
+}
+
+// GetOptionChainWithContext is the context aware variant of GetOptionChain.
+func (c *Client) GetOptionChainWithContext(ctx context.Context, im *InstrumentMaster, exchange, name string, expiry time.Time, underlying string) (*OptionChain, error) {
+	options := im.Query(InstrumentFilter{
+		Exchange:        exchange,
+		Name:            name,
+		InstrumentTypes: []string{InstrumentTypeCE, InstrumentTypePE},
+		Expiry:          expiry,
+	})
+
+	// Nothing to quote.
+	if len(options) == 0 && underlying == "" {
+		return &OptionChain{Name: name, Expiry: expiry}, nil
+	}
+
+	symbols := make([]string, 0, len(options)+1)
+	if underlying != "" {
+		symbols = append(symbols, underlying)
+	}
+	for _, inst := range options {
+		symbols = append(symbols, inst.Exchange+":"+inst.Tradingsymbol)
+	}
+
+	quotes, err := c.GetQuoteWithContext(ctx, symbols...)
+	if err != nil {
+		var partial PartialQuoteError
+		if !errors.As(err, &partial) {
+			return nil, err
+		}
+	}
+
+	oc := NewOptionChain(options, quotes, quotes[underlying].LastPrice)
+	if len(oc.Rows) == 0 {
+		oc.Name, oc.Expiry = name, expiry
+	}
+
+	return oc, err
+}
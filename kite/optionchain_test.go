@@ -0,0 +1,165 @@
+package kite_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/santoshanand/at-kite/kitetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionChain(t *testing.T) {
+	t.Parallel()
+	s := kitetest.NewServer()
+	defer s.Close()
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	weekly := kite.Time{Time: time.Date(2023, 7, 20, 0, 0, 0, 0, ist)}
+	monthly := kite.Time{Time: time.Date(2023, 7, 27, 0, 0, 0, 0, ist)}
+
+	instruments := kite.Instruments{
+		{InstrumentToken: 1, Tradingsymbol: "NIFTY23JULFUT", Name: "NIFTY", Expiry: monthly, InstrumentType: "FUT", Segment: "NFO-FUT", Exchange: kite.ExchangeNFO},
+		{InstrumentToken: 2, Tradingsymbol: "NIFTY23JUL110CE", Name: "NIFTY", Expiry: monthly, StrikePrice: 110, InstrumentType: "CE", Segment: "NFO-OPT", Exchange: kite.ExchangeNFO},
+	}
+	quotes := kite.Quote{"NSE:NIFTY 50": {InstrumentToken: 256265, LastPrice: 118}}
+
+	callOI := map[float64]float64{100: 10, 110: 20, 120: 30}
+	putOI := map[float64]float64{100: 60, 110: 20, 120: 10}
+	token := 10
+	for _, strike := range []float64{120, 100, 110} {
+		for _, typ := range []string{"CE", "PE"} {
+			sym := fmt.Sprintf("NIFTY23720%.0f%s", strike, typ)
+			oi := callOI[strike]
+			if typ == "PE" {
+				oi = putOI[strike]
+			}
+
+			token++
+			instruments = append(instruments, kite.Instrument{InstrumentToken: token, Tradingsymbol: sym, Name: "NIFTY", Expiry: weekly, StrikePrice: strike, InstrumentType: typ, Segment: "NFO-OPT", Exchange: kite.ExchangeNFO})
+			quotes["NFO:"+sym] = kite.QuoteData{InstrumentToken: token, LastPrice: strike / 10, OI: oi, Volume: 100}
+		}
+	}
+	s.SetInstruments(instruments)
+	s.SetQuotes(quotes)
+
+	kc := s.NewClient()
+	im := kite.NewInstrumentMaster(kc, "")
+	require.Nil(t, im.Load(context.Background()))
+
+	expiries := im.OptionExpiries(kite.ExchangeNFO, "NIFTY")
+	require.Equal(t, 2, len(expiries))
+	require.True(t, weekly.Equal(expiries[0]))
+	require.True(t, monthly.Equal(expiries[1]))
+
+	oc, err := kc.GetOptionChain(im, kite.ExchangeNFO, "NIFTY", expiries[0], "NSE:NIFTY 50")
+	require.Nil(t, err)
+	require.Equal(t, "NIFTY", oc.Name)
+	require.Equal(t, 118.0, oc.UnderlyingPrice)
+	require.Equal(t, 3, len(oc.Rows))
+	for i, strike := range []float64{100, 110, 120} {
+		r := oc.Rows[i]
+		require.Equal(t, strike, r.Strike)
+		require.Equal(t, callOI[strike], r.Call.OI)
+		require.Equal(t, putOI[strike], r.Put.OI)
+		require.Equal(t, strike/10, r.Call.LastPrice)
+		require.Equal(t, "PE", r.Put.Instrument.InstrumentType)
+	}
+
+	atm, ok := oc.ATM()
+	require.True(t, ok)
+	require.Equal(t, 120.0, atm.Strike)
+	require.Equal(t, 1.5, oc.PCR())
+	require.Equal(t, 110.0, oc.MaxPain())
+
+	// Only the monthly call is listed.
+	oc, err = kc.GetOptionChain(im, kite.ExchangeNFO, "NIFTY", expiries[1], "")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(oc.Rows))
+	require.Nil(t, oc.Rows[0].Put)
+	require.Equal(t, -1, oc.ATMIndex())
+	require.Equal(t, 0.0, oc.PCR())
+
+	// An expiry without options quotes nothing.
+	requests := len(s.Requests())
+	none := monthly.AddDate(0, 1, 0)
+	oc, err = kc.GetOptionChain(im, kite.ExchangeNFO, "NIFTY", none, "")
+	require.Nil(t, err)
+	require.Equal(t, "NIFTY", oc.Name)
+	require.True(t, none.Equal(oc.Expiry))
+	require.Equal(t, 0, len(oc.Rows))
+	require.Equal(t, requests, len(s.Requests()))
+}
+
+func TestOptionChainPartialQuotes(t *testing.T) {
+	t.Parallel()
+	s := kitetest.NewServer()
+	defer s.Close()
+
+	expiry := kite.Time{Time: time.Date(2023, 7, 20, 0, 0, 0, 0, time.FixedZone("IST", 5*60*60+30*60))}
+	var instruments kite.Instruments
+	quotes := kite.Quote{"NSE:NIFTY 50": {InstrumentToken: 256265, LastPrice: 19000}}
+	for i := 0; i < 600; i++ {
+		typ := []string{"CE", "PE"}[i%2]
+		strike := float64(18000 + i/2*10)
+		sym := fmt.Sprintf("NIFTY23720%.0f%s", strike, typ)
+		instruments = append(instruments, kite.Instrument{InstrumentToken: i + 1, Tradingsymbol: sym, Name: "NIFTY", Expiry: expiry, StrikePrice: strike, InstrumentType: typ, Segment: "NFO-OPT", Exchange: kite.ExchangeNFO})
+		quotes["NFO:"+sym] = kite.QuoteData{InstrumentToken: i + 1, LastPrice: 1}
+	}
+	s.SetInstruments(instruments)
+	s.SetQuotes(quotes)
+
+	kc := s.NewClient()
+	kc.SetRetryPolicy(kite.NoRetryPolicy())
+	im := kite.NewInstrumentMaster(kc, "")
+	require.Nil(t, im.Load(context.Background()))
+
+	// One of the two batches of quotes fails.
+	s.Inject(kitetest.Fault{Path: kite.URIGetQuote, Times: 1, Status: http.StatusInternalServerError, ErrorType: kite.GeneralError, Message: "Something went wrong"})
+	oc, err := kc.GetOptionChainWithContext(context.Background(), im, kite.ExchangeNFO, "NIFTY", expiry.Time, "NSE:NIFTY 50")
+
+	var partial kite.PartialQuoteError
+	require.True(t, errors.As(err, &partial))
+	require.NotNil(t, oc)
+	require.Equal(t, 300, len(oc.Rows))
+
+	missing := make(map[string]bool)
+	for _, sym := range partial.Instruments() {
+		missing[sym] = true
+	}
+
+	quoted := 0
+	for _, r := range oc.Rows {
+		for _, leg := range []*kite.OptionLeg{r.Call, r.Put} {
+			require.Equal(t, missing["NFO:"+leg.Instrument.Tradingsymbol], leg.LastPrice == 0)
+			if leg.LastPrice > 0 {
+				quoted++
+			}
+		}
+	}
+	require.True(t, quoted > 0)
+
+	missingOptions := len(missing)
+	if missing["NSE:NIFTY 50"] {
+		missingOptions--
+		require.Equal(t, 0.0, oc.UnderlyingPrice)
+	} else {
+		require.Equal(t, 19000.0, oc.UnderlyingPrice)
+	}
+	require.Equal(t, 600-missingOptions, quoted)
+}
+
+func TestOptionChainATM(t *testing.T) {
+	t.Parallel()
+	oc := kite.OptionChain{UnderlyingPrice: 115, Rows: []kite.OptionChainRow{{Strike: 100}, {Strike: 110}, {Strike: 120}}}
+	require.Equal(t, 1, oc.ATMIndex())
+
+	oc.UnderlyingPrice = 90
+	require.Equal(t, 0, oc.ATMIndex())
+
+	require.Equal(t, 0.0, (&kite.OptionChain{}).MaxPain())
+}
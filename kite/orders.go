@@ -1,6 +1,7 @@
 package kite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -150,41 +151,59 @@ type GST struct {
 
 // GetOrders gets list of orders.
 func (c *Client) GetOrders() (Orders, error) {
-	var orders Orders
-	err := c.doEnvelope(http.MethodGet, URIGetOrders, nil, nil, &orders)
-	return orders, err
+	return c.GetOrdersWithContext(context.Background())
 }
 
-// GetOrdersOms - get oms orders
-func (c *Client) GetOrdersOms() (Orders, error) {
+// GetOrdersWithContext is the context aware variant of GetOrders.
+func (c *Client) GetOrdersWithContext(ctx context.Context) (Orders, error) {
 	var orders Orders
-	err := c.doEnvelope(http.MethodGet, URIGetOMSOrders, nil, nil, &orders)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetOrders, nil, nil, &orders)
 	return orders, err
 }
 
 // GetTrades gets list of trades.
 func (c *Client) GetTrades() (Trades, error) {
+	return c.GetTradesWithContext(context.Background())
+}
+
+// GetTradesWithContext is the context aware variant of GetTrades.
+func (c *Client) GetTradesWithContext(ctx context.Context) (Trades, error) {
 	var trades Trades
-	err := c.doEnvelope(http.MethodGet, URIGetTrades, nil, nil, &trades)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetTrades, nil, nil, &trades)
 	return trades, err
 }
 
 // GetOrderHistory gets history of an individual order.
 func (c *Client) GetOrderHistory(OrderID string) ([]Order, error) {
+	return c.GetOrderHistoryWithContext(context.Background(), OrderID)
+}
+
+// GetOrderHistoryWithContext is the context aware variant of GetOrderHistory.
+func (c *Client) GetOrderHistoryWithContext(ctx context.Context, OrderID string) ([]Order, error) {
 	var orderHistory []Order
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetOrderHistory, OrderID), nil, nil, &orderHistory)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetOrderHistory, OrderID), nil, nil, &orderHistory)
 	return orderHistory, err
 }
 
 // GetOrderTrades gets list of trades executed for a particular order.
 func (c *Client) GetOrderTrades(OrderID string) ([]Trade, error) {
+	return c.GetOrderTradesWithContext(context.Background(), OrderID)
+}
+
+// GetOrderTradesWithContext is the context aware variant of GetOrderTrades.
+func (c *Client) GetOrderTradesWithContext(ctx context.Context, OrderID string) ([]Trade, error) {
 	var orderTrades []Trade
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIGetOrderTrades, OrderID), nil, nil, &orderTrades)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIGetOrderTrades, OrderID), nil, nil, &orderTrades)
 	return orderTrades, err
 }
 
 // ChargeOrders - place a request for order charge
 func (c *Client) ChargeOrders(chargeOrderParams []ChargeOrderParams) ([]ChargeOrderResponse, error) {
+	return c.ChargeOrdersWithContext(context.Background(), chargeOrderParams)
+}
+
+// ChargeOrdersWithContext is the context aware variant of ChargeOrders.
+func (c *Client) ChargeOrdersWithContext(ctx context.Context, chargeOrderParams []ChargeOrderParams) ([]ChargeOrderResponse, error) {
 	var (
 		chargeOrderResponse []ChargeOrderResponse
 		// params              url.Values
@@ -201,8 +220,13 @@ func (c *Client) ChargeOrders(chargeOrderParams []ChargeOrderParams) ([]ChargeOr
 	// b = []byte(str)
 	headers := http.Header{}
 	headers.Add("content-type", "application/json")
-	resp, err := c.doRaw(http.MethodPost, URIPlaceCharges, b, headers)
-	err = readEnvelope(resp, &chargeOrderResponse)
+	// Charges are only served by the web OMS.
+	resp, err := c.doRaw(WithBackend(ctx, BackendOMS), http.MethodPost, URIPlaceCharges, b, headers)
+	if err != nil {
+		return chargeOrderResponse, err
+	}
+
+	err = c.readEnvelope(resp, &chargeOrderResponse)
 	if err != nil {
 		if _, ok := err.(Error); !ok {
 			fmt.Printf("Error parsing JSON response: %v", err)
@@ -213,6 +237,11 @@ func (c *Client) ChargeOrders(chargeOrderParams []ChargeOrderParams) ([]ChargeOr
 
 // PlaceOrder places an order.
 func (c *Client) PlaceOrder(variety string, orderParams OrderParams) (OrderResponse, error) {
+	return c.PlaceOrderWithContext(context.Background(), variety, orderParams)
+}
+
+// PlaceOrderWithContext is the context aware variant of PlaceOrder.
+func (c *Client) PlaceOrderWithContext(ctx context.Context, variety string, orderParams OrderParams) (OrderResponse, error) {
 	var (
 		orderResponse OrderResponse
 		params        url.Values
@@ -223,12 +252,17 @@ func (c *Client) PlaceOrder(variety string, orderParams OrderParams) (OrderRespo
 		return orderResponse, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	err = c.doEnvelope(http.MethodPost, fmt.Sprintf(URIPlaceOrder, variety), params, nil, &orderResponse)
+	err = c.doEnvelope(ctx, http.MethodPost, fmt.Sprintf(URIPlaceOrder, variety), params, nil, &orderResponse)
 	return orderResponse, err
 }
 
 // ModifyOrder modifies an order.
 func (c *Client) ModifyOrder(variety string, orderID string, orderParams OrderParams) (OrderResponse, error) {
+	return c.ModifyOrderWithContext(context.Background(), variety, orderID, orderParams)
+}
+
+// ModifyOrderWithContext is the context aware variant of ModifyOrder.
+func (c *Client) ModifyOrderWithContext(ctx context.Context, variety string, orderID string, orderParams OrderParams) (OrderResponse, error) {
 	var (
 		orderResponse OrderResponse
 		params        url.Values
@@ -239,12 +273,17 @@ func (c *Client) ModifyOrder(variety string, orderID string, orderParams OrderPa
 		return orderResponse, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	err = c.doEnvelope(http.MethodPut, fmt.Sprintf(URIModifyOrder, variety, orderID), params, nil, &orderResponse)
+	err = c.doEnvelope(ctx, http.MethodPut, fmt.Sprintf(URIModifyOrder, variety, orderID), params, nil, &orderResponse)
 	return orderResponse, err
 }
 
 // CancelOrder cancels/exits an order.
 func (c *Client) CancelOrder(variety string, orderID string, parentOrderID *string) (OrderResponse, error) {
+	return c.CancelOrderWithContext(context.Background(), variety, orderID, parentOrderID)
+}
+
+// CancelOrderWithContext is the context aware variant of CancelOrder.
+func (c *Client) CancelOrderWithContext(ctx context.Context, variety string, orderID string, parentOrderID *string) (OrderResponse, error) {
 	var (
 		orderResponse OrderResponse
 		params        url.Values
@@ -256,11 +295,16 @@ func (c *Client) CancelOrder(variety string, orderID string, parentOrderID *stri
 		params.Add("parent_order_id", *parentOrderID)
 	}
 
-	err := c.doEnvelope(http.MethodDelete, fmt.Sprintf(URICancelOrder, variety, orderID), params, nil, &orderResponse)
+	err := c.doEnvelope(ctx, http.MethodDelete, fmt.Sprintf(URICancelOrder, variety, orderID), params, nil, &orderResponse)
 	return orderResponse, err
 }
 
 // ExitOrder is an alias for CancelOrder which is used to cancel/exit an order.
 func (c *Client) ExitOrder(variety string, orderID string, parentOrderID *string) (OrderResponse, error) {
-	return c.CancelOrder(variety, orderID, parentOrderID)
+	return c.ExitOrderWithContext(context.Background(), variety, orderID, parentOrderID)
+}
+
+// ExitOrderWithContext is the context aware variant of ExitOrder.
+func (c *Client) ExitOrderWithContext(ctx context.Context, variety string, orderID string, parentOrderID *string) (OrderResponse, error) {
+	return c.CancelOrderWithContext(ctx, variety, orderID, parentOrderID)
 }
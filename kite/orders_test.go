@@ -1,9 +1,11 @@
-package kite
+package kite_test
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
+	"github.com/santoshanand/at-kite/kite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +36,7 @@ func TestGetOrders(t *testing.T) {
 
 func TestGetOrdersOMS(t *testing.T) {
 	t.Parallel()
-	orders, err := getKite().GetOrdersOms()
+	orders, err := getKite().GetOrdersWithContext(kite.WithBackend(context.Background(), kite.BackendOMS))
 	if err != nil {
 		t.Errorf("Error while fetching orders. %v", err)
 	}
@@ -97,7 +99,7 @@ func TestGetOrderTrades(t *testing.T) {
 
 func TestPlaceOrder(t *testing.T) {
 	t.Parallel()
-	params := OrderParams{
+	params := kite.OrderParams{
 		Exchange:          "test",
 		Tradingsymbol:     "test",
 		Validity:          "test",
@@ -124,7 +126,7 @@ func TestPlaceOrder(t *testing.T) {
 
 func TestModifyOrder(t *testing.T) {
 	t.Parallel()
-	params := OrderParams{
+	params := kite.OrderParams{
 		Exchange:          "test",
 		Tradingsymbol:     "test",
 		Validity:          "test",
@@ -153,7 +155,7 @@ func TestCancelOrder(t *testing.T) {
 	t.Parallel()
 	parentOrderID := "test"
 
-	orderResponse, err := getKite().CancelOrder("test", "test", &parentOrderID)
+	orderResponse, err := getKite().CancelOrder("regular", "test-cancel", &parentOrderID)
 	if err != nil || orderResponse.OrderID == "" {
 		t.Errorf("Error while placing cancel order. %v", err)
 	}
@@ -163,7 +165,7 @@ func TestExitOrder(t *testing.T) {
 	t.Parallel()
 	parentOrderID := "test"
 
-	orderResponse, err := getKite().ExitOrder("test", "test", &parentOrderID)
+	orderResponse, err := getKite().ExitOrder("co", "test-exit", &parentOrderID)
 	if err != nil {
 		t.Errorf("Error while placing order. %v", err)
 	}
@@ -186,7 +188,7 @@ func TestIssue64(t *testing.T) {
 		t.Errorf("Error while marshalling order. %v", err)
 	}
 
-	var outOrd Order
+	var outOrd kite.Order
 	err = json.Unmarshal(js, &outOrd)
 	if err != nil {
 		t.Errorf("Error while unmarshalling order. %v", err)
@@ -199,7 +201,7 @@ func TestIssue64(t *testing.T) {
 
 func TestChargeOrder(t *testing.T) {
 	t.Parallel()
-	params := []ChargeOrderParams{{
+	params := []kite.ChargeOrderParams{{
 		AveragePrice:    91,
 		Exchange:        "NFO",
 		OrderID:         "230714200050319",
@@ -1,6 +1,7 @@
 package kite
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -97,27 +98,35 @@ type ConvertPositionParams struct {
 
 // GetHoldings gets a list of holdings.
 func (c *Client) GetHoldings() (Holdings, error) {
+	return c.GetHoldingsWithContext(context.Background())
+}
+
+// GetHoldingsWithContext is the context aware variant of GetHoldings.
+func (c *Client) GetHoldingsWithContext(ctx context.Context) (Holdings, error) {
 	var holdings Holdings
-	err := c.doEnvelope(http.MethodGet, URIGetHoldings, nil, nil, &holdings)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetHoldings, nil, nil, &holdings)
 	return holdings, err
 }
 
 // GetPositions gets user positions.
 func (c *Client) GetPositions() (Positions, error) {
-	var positions Positions
-	err := c.doEnvelope(http.MethodGet, URIGetPositions, nil, nil, &positions)
-	return positions, err
+	return c.GetPositionsWithContext(context.Background())
 }
 
-// GetPositionsOMS gets user positions.
-func (c *Client) GetPositionsOMS() (Positions, error) {
+// GetPositionsWithContext is the context aware variant of GetPositions.
+func (c *Client) GetPositionsWithContext(ctx context.Context) (Positions, error) {
 	var positions Positions
-	err := c.doEnvelope(http.MethodGet, URIGetPositionsOMS, nil, nil, &positions)
+	err := c.doEnvelope(ctx, http.MethodGet, URIGetPositions, nil, nil, &positions)
 	return positions, err
 }
 
 // ConvertPosition converts postion's product type.
 func (c *Client) ConvertPosition(positionParams ConvertPositionParams) (bool, error) {
+	return c.ConvertPositionWithContext(context.Background(), positionParams)
+}
+
+// ConvertPositionWithContext is the context aware variant of ConvertPosition.
+func (c *Client) ConvertPositionWithContext(ctx context.Context, positionParams ConvertPositionParams) (bool, error) {
 	var (
 		b      bool
 		err    error
@@ -128,7 +137,7 @@ func (c *Client) ConvertPosition(positionParams ConvertPositionParams) (bool, er
 		return false, NewError(InputError, fmt.Sprintf("Error decoding order params: %v", err), nil)
 	}
 
-	if err = c.doEnvelope(http.MethodPut, URIConvertPosition, params, nil, nil); err == nil {
+	if err = c.doEnvelope(ctx, http.MethodPut, URIConvertPosition, params, nil, nil); err == nil {
 		b = true
 	}
 
@@ -1,7 +1,9 @@
-package kite
+package kite_test
 
 import (
 	"testing"
+
+	"github.com/santoshanand/at-kite/kite"
 )
 
 func TestGetPositions(t *testing.T) {
@@ -43,7 +45,7 @@ func TestGetHoldings(t *testing.T) {
 
 func TestConvertPosition(t *testing.T) {
 	t.Parallel()
-	params := ConvertPositionParams{
+	params := kite.ConvertPositionParams{
 		Exchange:        "test",
 		TradingSymbol:   "test",
 		OldProduct:      "test",
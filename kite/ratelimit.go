@@ -0,0 +1,218 @@
+package kite
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups API endpoints which share a rate limit.
+type EndpointClass string
+
+const (
+	// EndpointQuote covers the quote, LTP and OHLC endpoints.
+	EndpointQuote EndpointClass = "quote"
+	// EndpointHistorical covers historical candle requests.
+	EndpointHistorical EndpointClass = "historical"
+	// EndpointOrder covers order placement, modification and cancellation.
+	EndpointOrder EndpointClass = "order"
+	// EndpointDefault covers every other endpoint.
+	EndpointDefault EndpointClass = "default"
+)
+
+// RateLimitMode decides what happens when a request exceeds the rate limit.
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks the caller until the request can be sent or
+	// its context is done.
+	RateLimitWait RateLimitMode = iota
+	// RateLimitFailFast returns an error right away instead of waiting.
+	RateLimitFailFast
+)
+
+// RateLimit is the allowed request rate for an endpoint class.
+type RateLimit struct {
+	// Rate is the number of requests allowed per second.
+	Rate float64
+	// Burst is the number of requests which can be sent at once. Defaults to 1.
+	Burst int
+}
+
+// RateLimitConfig configures a RateLimiter. Classes missing from Limits
+// are not limited.
+type RateLimitConfig struct {
+	Limits map[EndpointClass]RateLimit
+	Mode   RateLimitMode
+}
+
+// RateLimitStats holds the counters for an endpoint class.
+type RateLimitStats struct {
+	// Requests is the number of requests which were let through.
+	Requests int64
+	// Waited is the number of requests which had to wait.
+	Waited int64
+	// Rejected is the number of requests rejected in fail fast mode or
+	// abandoned because the context was done while waiting.
+	Rejected int64
+	// TotalWait is the cumulative time callers spent waiting.
+	TotalWait time.Duration
+	// MaxWait is the longest time a single caller waited.
+	MaxWait time.Duration
+}
+
+// DefaultRateLimits returns the limits documented by Kite Connect:
+// 1 req/sec for quotes, 3 req/sec for historical candles and
+// 10 req/sec for orders and everything else.
+func DefaultRateLimits() RateLimitConfig {
+	return RateLimitConfig{
+		Limits: map[EndpointClass]RateLimit{
+			EndpointQuote:      {Rate: 1, Burst: 1},
+			EndpointHistorical: {Rate: 3, Burst: 3},
+			EndpointOrder:      {Rate: 10, Burst: 10},
+			EndpointDefault:    {Rate: 10, Burst: 10},
+		},
+		Mode: RateLimitWait,
+	}
+}
+
+// RateLimiter is a token bucket rate limiter keyed by endpoint class.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mode    RateLimitMode
+	buckets map[EndpointClass]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  RateLimitStats
+}
+
+// NewRateLimiter returns a new RateLimiter for the given config.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{
+		mode:    cfg.Mode,
+		buckets: make(map[EndpointClass]*tokenBucket, len(cfg.Limits)),
+		now:     time.Now,
+	}
+
+	for class, lim := range cfg.Limits {
+		if lim.Rate <= 0 {
+			continue
+		}
+
+		burst := float64(lim.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+
+		l.buckets[class] = &tokenBucket{
+			rate:   lim.Rate,
+			burst:  burst,
+			tokens: burst,
+		}
+	}
+
+	return l
+}
+
+// Wait blocks until a request of the given class is allowed. In fail fast
+// mode it returns an error instead of waiting.
+func (l *RateLimiter) Wait(ctx context.Context, class EndpointClass) error {
+	b, ok := l.buckets[class]
+	if !ok {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := l.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+
+	if wait > 0 && l.mode == RateLimitFailFast {
+		b.stats.Rejected++
+		b.mu.Unlock()
+		return newError(NetworkError, fmt.Sprintf("Rate limit exceeded for %s requests, retry in %v.", class, wait), http.StatusTooManyRequests, nil)
+	}
+
+	// Reserve the token up front. Concurrent callers queue up behind it.
+	b.tokens--
+	b.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			// Return the reserved token.
+			b.mu.Lock()
+			b.tokens++
+			b.stats.Rejected++
+			b.mu.Unlock()
+			return wrapError(ContextError, "Request aborted", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	b.mu.Lock()
+	b.stats.Requests++
+	if wait > 0 {
+		b.stats.Waited++
+		b.stats.TotalWait += wait
+		if wait > b.stats.MaxWait {
+			b.stats.MaxWait = wait
+		}
+	}
+	b.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns a snapshot of the counters of every limited endpoint class.
+func (l *RateLimiter) Stats() map[EndpointClass]RateLimitStats {
+	out := make(map[EndpointClass]RateLimitStats, len(l.buckets))
+	for class, b := range l.buckets {
+		b.mu.Lock()
+		out[class] = b.stats
+		b.mu.Unlock()
+	}
+
+	return out
+}
+
+var historicalURIPrefix = URIGetHistorical[:strings.Index(URIGetHistorical, "%")]
+
+// GetEndpointClass returns the rate limit class of a request.
+func GetEndpointClass(method, uri string) EndpointClass {
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		uri = uri[:i]
+	}
+
+	switch {
+	case uri == URIGetQuote || strings.HasPrefix(uri, URIGetQuote+"/"):
+		return EndpointQuote
+	case strings.HasPrefix(uri, historicalURIPrefix):
+		return EndpointHistorical
+	case method != http.MethodGet && strings.HasPrefix(uri, URIGetOrders+"/"):
+		return EndpointOrder
+	}
+
+	return EndpointDefault
+}
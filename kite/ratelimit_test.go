@@ -0,0 +1,132 @@
+package kite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEndpointClass(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		method string
+		uri    string
+		want   EndpointClass
+	}{
+		{http.MethodGet, URIGetQuote, EndpointQuote},
+		{http.MethodGet, URIGetLTP, EndpointQuote},
+		{http.MethodGet, URIGetOHLC, EndpointQuote},
+		{http.MethodGet, fmt.Sprintf(URIGetHistorical, 256265, "minute"), EndpointHistorical},
+		{http.MethodPost, fmt.Sprintf(URIPlaceOrder, VarietyRegular), EndpointOrder},
+		{http.MethodPut, fmt.Sprintf(URIModifyOrder, VarietyRegular, "1"), EndpointOrder},
+		{http.MethodDelete, fmt.Sprintf(URICancelOrder, VarietyRegular, "1"), EndpointOrder},
+		{http.MethodGet, fmt.Sprintf(URIGetOrderHistory, "1"), EndpointDefault},
+		{http.MethodGet, URIGetOrders, EndpointDefault},
+		{http.MethodGet, URIGetInstruments, EndpointDefault},
+		{http.MethodPost, URIOrderMargins + "?mode=compact", EndpointDefault},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, GetEndpointClass(tt.method, tt.uri), "%s %s", tt.method, tt.uri)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	t.Parallel()
+	l := NewRateLimiter(RateLimitConfig{
+		Limits: map[EndpointClass]RateLimit{EndpointQuote: {Rate: 50, Burst: 1}},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.Nil(t, l.Wait(context.Background(), EndpointQuote))
+	}
+	require.True(t, time.Since(start) >= 35*time.Millisecond)
+
+	// Unlimited classes are let through right away.
+	require.Nil(t, l.Wait(context.Background(), EndpointOrder))
+
+	stats := l.Stats()[EndpointQuote]
+	require.Equal(t, int64(3), stats.Requests)
+	require.Equal(t, int64(2), stats.Waited)
+	require.True(t, stats.TotalWait > 0)
+	require.True(t, stats.MaxWait <= stats.TotalWait)
+}
+
+func TestRateLimiterFailFast(t *testing.T) {
+	t.Parallel()
+	l := NewRateLimiter(RateLimitConfig{
+		Limits: map[EndpointClass]RateLimit{EndpointOrder: {Rate: 1, Burst: 2}},
+		Mode:   RateLimitFailFast,
+	})
+
+	require.Nil(t, l.Wait(context.Background(), EndpointOrder))
+	require.Nil(t, l.Wait(context.Background(), EndpointOrder))
+
+	err := l.Wait(context.Background(), EndpointOrder)
+	require.Error(t, err)
+	require.Equal(t, http.StatusTooManyRequests, err.(Error).Code)
+	require.Equal(t, int64(1), l.Stats()[EndpointOrder].Rejected)
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	t.Parallel()
+	l := NewRateLimiter(RateLimitConfig{
+		Limits: map[EndpointClass]RateLimit{EndpointHistorical: {Rate: 0.1}},
+	})
+	require.Nil(t, l.Wait(context.Background(), EndpointHistorical))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.Wait(ctx, EndpointHistorical)
+	require.Error(t, err)
+	require.Equal(t, ContextError, err.(Error).ErrorType)
+}
+
+func TestQuoteBatchesBoundedByRateLimit(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight, hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"status":"success","data":{"NSE:` + r.URL.Query()["i"][0] + `":{"instrument_token":1}}}`))
+	}))
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRateLimiter(NewRateLimiter(RateLimitConfig{
+		Limits: map[EndpointClass]RateLimit{EndpointQuote: {Rate: 1000, Burst: 2}},
+	}))
+
+	symbols := make([]string, 6*quoteLimits[URIGetLTP])
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("I%d", i)
+	}
+
+	ltp, err := kc.GetLTP(symbols...)
+	require.Nil(t, err)
+	require.Equal(t, int32(6), atomic.LoadInt32(&hits))
+	require.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+	require.Equal(t, 6, len(ltp))
+	require.Equal(t, 2, kc.quoteWorkers())
+
+	kc.SetRateLimiter(nil)
+	require.Equal(t, defaultQuoteWorkers, kc.quoteWorkers())
+}
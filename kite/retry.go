@@ -0,0 +1,201 @@
+package kite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DedupFunc reports whether a previous attempt of a non idempotent request
+// (placing or modifying an order, placing a GTT etc.) has already taken effect
+// on the server. It is consulted before such a request is retried. Returning
+// true or an error stops the retries and the original failure is returned.
+type DedupFunc func(ctx context.Context, method, rURL string, reqBody []byte) (bool, error)
+
+// RetryPolicy configures how failed HTTP requests are retried.
+//
+// Idempotent requests (GET, HEAD, OPTIONS) are retried automatically. All other
+// requests are only retried when a Dedup strategy is set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles on every
+	// subsequent retry and is capped at MaxDelay. Each wait is jittered to a
+	// random duration in the range [delay/2, delay]. A Retry-After sent by the
+	// server is waited instead, also capped at MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// RetryStatuses is the list of HTTP status codes which are retried.
+	RetryStatuses []int
+
+	// Dedup opts non idempotent requests into retries.
+	Dedup DedupFunc
+}
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 200 * time.Millisecond
+	defaultRetryMaxDelay    = 2 * time.Second
+)
+
+// DefaultRetryPolicy returns the retry policy used by new clients. It retries
+// idempotent requests up to 3 times on connection resets, timeouts and
+// 429, 502, 503 and 504 responses.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// NoRetryPolicy returns a policy which makes exactly one attempt per request.
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// shouldRetry checks if an attempt failed in a way that is worth retrying,
+// either with a transient transport error or a retryable status code.
+func (p RetryPolicy) shouldRetry(resp HTTPResponse, cause error) bool {
+	if cause != nil {
+		return isTransientNetError(cause)
+	}
+
+	if resp.Response == nil {
+		return false
+	}
+
+	for _, s := range p.RetryStatuses {
+		if resp.Response.StatusCode == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the wait before the next attempt. Retry-After sent by the
+// server takes precedence over the exponential delay, up to MaxDelay.
+func (p RetryPolicy) backoff(attempt int, resp HTTPResponse) time.Duration {
+	if resp.Response != nil {
+		if secs, err := strconv.Atoi(resp.Response.Header.Get("Retry-After")); err == nil && secs > 0 {
+			delay := time.Duration(secs) * time.Second
+			if p.MaxDelay > 0 && (delay > p.MaxDelay || delay/time.Second != time.Duration(secs)) {
+				delay = p.MaxDelay
+			}
+
+			return delay
+		}
+	}
+
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	// Half of the delay is fixed and the rest is jittered so that concurrent
+	// callers don't retry in lockstep.
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// isIdempotent reports whether a request with the given method can be
+// safely sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// isTransientNetError reports whether a transport error is likely to go away
+// on a retry, like a connection reset by the peer or a timeout.
+func isTransientNetError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var nErr net.Error
+	if errors.As(err, &nErr) && nErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// OrderTagDedup returns a DedupFunc for order placement which looks up the
+// orderbook for an order carrying the same tag as the request. Orders placed
+// without a tag can't be deduplicated and are not retried.
+func OrderTagDedup(c *Client) DedupFunc {
+	return func(ctx context.Context, method, rURL string, reqBody []byte) (bool, error) {
+		u, err := url.Parse(rURL)
+		if err != nil {
+			return false, err
+		}
+
+		if method != http.MethodPost || !strings.Contains(u.Path, URIGetOrders+"/") {
+			return false, errors.New("dedup is only supported for order placement")
+		}
+
+		params, err := url.ParseQuery(string(reqBody))
+		if err != nil {
+			return false, err
+		}
+
+		tag := params.Get("tag")
+		if tag == "" {
+			return false, errors.New("order has no tag to dedup on")
+		}
+
+		orders, err := c.GetOrdersWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, o := range orders {
+			if o.Tag == tag {
+				return true, nil
+			}
+
+			for _, t := range o.Tags {
+				if t == tag {
+					return true, nil
+				}
+			}
+		}
+
+		return false, nil
+	}
+}
@@ -0,0 +1,172 @@
+package kite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+// flakyServer fails the first `failures` requests with status and then
+// responds with a successful orderbook or order placement envelope.
+func flakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"status":"error","error_type":"NetworkException","message":"unavailable"}`))
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"status":"success","data":[{"order_id":"1"}]}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"order_id":"1"}}`))
+	}))
+	return ts, &hits
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	t.Parallel()
+	ts, hits := flakyServer(2, http.StatusServiceUnavailable)
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRetryPolicy(testRetryPolicy())
+
+	_, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	t.Parallel()
+	ts, hits := flakyServer(5, http.StatusBadGateway)
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRetryPolicy(testRetryPolicy())
+
+	_, err := kc.GetOrders()
+	require.Error(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestNoRetryForNonIdempotentRequest(t *testing.T) {
+	t.Parallel()
+	ts, hits := flakyServer(1, http.StatusServiceUnavailable)
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRetryPolicy(testRetryPolicy())
+
+	_, err := kc.PlaceOrder(VarietyRegular, OrderParams{Tag: "t1"})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
+
+func TestRetryNonIdempotentWithDedup(t *testing.T) {
+	t.Parallel()
+	ts, hits := flakyServer(1, http.StatusServiceUnavailable)
+	defer ts.Close()
+
+	var checked int32
+	p := testRetryPolicy()
+	p.Dedup = func(ctx context.Context, method, rURL string, reqBody []byte) (bool, error) {
+		atomic.AddInt32(&checked, 1)
+		return false, nil
+	}
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRetryPolicy(p)
+
+	res, err := kc.PlaceOrder(VarietyRegular, OrderParams{Tag: "t1"})
+	require.Nil(t, err)
+	require.Equal(t, "1", res.OrderID)
+	require.Equal(t, int32(2), atomic.LoadInt32(hits))
+	require.Equal(t, int32(1), atomic.LoadInt32(&checked))
+}
+
+func TestOrderTagDedup(t *testing.T) {
+	t.Parallel()
+	var placed int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"status":"success","data":[{"order_id":"1","tag":"t1"}]}`))
+			return
+		}
+		// The order goes through but the response is lost.
+		atomic.AddInt32(&placed, 1)
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	p := testRetryPolicy()
+	p.Dedup = OrderTagDedup(kc)
+	kc.SetRetryPolicy(p)
+
+	_, err := kc.PlaceOrder(VarietyRegular, OrderParams{Tag: "t1"})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&placed))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		d := p.backoff(attempt+1, HTTPResponse{})
+		require.True(t, d >= max*time.Millisecond/2 && d <= max*time.Millisecond, "attempt %d: %v", attempt+1, d)
+	}
+
+	resp := HTTPResponse{Response: &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}}
+	require.Equal(t, time.Second, p.backoff(1, resp))
+
+	p.MaxDelay = 5 * time.Second
+	require.Equal(t, 3*time.Second, p.backoff(1, resp))
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	t.Parallel()
+	p := RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+
+	for _, v := range []string{"3600", "9223372036854775807"} {
+		resp := HTTPResponse{Response: &http.Response{Header: http.Header{"Retry-After": []string{v}}}}
+		require.Equal(t, 2*time.Second, p.backoff(1, resp), v)
+	}
+}
+
+func TestRetryTakesRateLimitToken(t *testing.T) {
+	t.Parallel()
+	ts, hits := flakyServer(1, http.StatusTooManyRequests)
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRetryPolicy(testRetryPolicy())
+	kc.SetRateLimiter(NewRateLimiter(RateLimitConfig{
+		Limits: map[EndpointClass]RateLimit{EndpointDefault: {Rate: 1000, Burst: 10}},
+	}))
+
+	_, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(hits))
+	require.Equal(t, int64(2), kc.RateLimitStats()[EndpointDefault].Requests)
+}
@@ -22,6 +22,7 @@ type Ticker struct {
 
 	apiKey      string
 	accessToken string
+	tokenStore  TokenStore
 
 	url                 url.URL
 	callbacks           callbacks
@@ -33,21 +34,34 @@ type Ticker struct {
 
 	reconnectAttempt int
 
-	subscribedTokens map[uint32]Mode
+	// mu guards writes to Conn and the subscriptions, which are changed
+	// from user goroutines while the serve loop resubscribes.
+	mu            sync.Mutex
+	connected     bool
+	subscriptions map[uint32]*subscription
+	// lastRequest is the tokens of the last request sent, which a server
+	// error is taken to be about.
+	lastRequest []uint32
+	// unacked is the number of subscriptions which aren't active, read
+	// without mu so ticks only lock it while some are.
+	unacked int32
+
+	queues tickerQueues
 
 	cancel context.CancelFunc
 }
 
 // callbacks represents callbacks available in ticker.
 type callbacks struct {
-	onTick        func(Tick)
-	onMessage     func(int, []byte)
-	onNoReconnect func(int)
-	onReconnect   func(int, time.Duration)
-	onConnect     func()
-	onClose       func(int, string)
-	onError       func(error)
-	onOrderUpdate func(Order)
+	onTick         func(Tick)
+	onMessage      func(int, []byte)
+	onNoReconnect  func(int)
+	onReconnect    func(int, time.Duration)
+	onConnect      func()
+	onClose        func(int, string)
+	onError        func(error)
+	onOrderUpdate  func(Order)
+	onSubscription func(SubscriptionStatus)
 }
 
 type tickerInput struct {
@@ -153,7 +167,7 @@ func NewTicker(accessToken string) *Ticker {
 		reconnectMaxDelay:   defaultReconnectMaxDelay,
 		reconnectMaxRetries: defaultReconnectMaxAttempts,
 		connectTimeout:      defaultConnectTimeout,
-		subscribedTokens:    map[uint32]Mode{},
+		subscriptions:       map[uint32]*subscription{},
 	}
 
 	return ticker
@@ -169,6 +183,24 @@ func (t *Ticker) SetAccessToken(aToken string) {
 	t.accessToken = aToken
 }
 
+// SetTokenStore sets the store the access token is read from on every
+// connect and reconnect.
+func (t *Ticker) SetTokenStore(s TokenStore) {
+	t.tokenStore = s
+}
+
+// getAccessToken returns the access token from the token store, falling back
+// to the one set on the ticker if none is stored or it has expired.
+func (t *Ticker) getAccessToken() string {
+	if t.tokenStore != nil {
+		if tok, err := t.tokenStore.Load(); err == nil && tok.AccessToken != "" && !tok.Expired(time.Now()) {
+			return tok.AccessToken
+		}
+	}
+
+	return t.accessToken
+}
+
 // SetConnectTimeout sets default timeout for initial connect handshake
 func (t *Ticker) SetConnectTimeout(val time.Duration) {
 	t.connectTimeout = val
@@ -246,6 +278,15 @@ func (t *Ticker) Serve() {
 func (t *Ticker) ServeWithContext(ctx context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
 	t.cancel = cancel
+	defer cancel()
+
+	// Close the channels once stopped, which also releases a read blocked
+	// on a full channel.
+	gen := t.queueGen()
+	go func() {
+		<-ctx.Done()
+		t.closeQueues(gen)
+	}()
 
 	for {
 		select {
@@ -278,7 +319,7 @@ func (t *Ticker) ServeWithContext(ctx context.Context) {
 			// Prepare ticker URL with required params.
 			q := t.url.Query()
 			q.Set("api_key", t.apiKey)
-			q.Set("access_token", t.accessToken)
+			q.Set("access_token", t.getAccessToken())
 			q.Set("user_id", "YA9556")
 			// q.Set("uid", "1671184933040")
 			// q.Set("user-agent", "kite3-web")
@@ -298,6 +339,8 @@ func (t *Ticker) ServeWithContext(ctx context.Context) {
 					t.reconnectAttempt++
 					continue
 				}
+
+				return
 			}
 
 			// Close the connection when its done.
@@ -308,16 +351,20 @@ func (t *Ticker) ServeWithContext(ctx context.Context) {
 			}()
 
 			// Assign the current connection to the instance.
+			t.mu.Lock()
 			t.Conn = conn
+			t.connected = true
+			t.mu.Unlock()
+
+			// Send the subscriptions requested before connecting, or those
+			// of the previous connection.
+			if err := t.Resubscribe(); err != nil {
+				t.triggerError(err)
+			}
 
 			// Trigger connect callback.
 			t.triggerConnect()
 
-			// Resubscribe to stored tokens
-			if t.reconnectAttempt > 0 {
-				t.Resubscribe()
-			}
-
 			// Reset auto reconnect vars
 			t.reconnectAttempt = 0
 
@@ -341,6 +388,7 @@ func (t *Ticker) ServeWithContext(ctx context.Context) {
 
 			// Wait for go routines to finish before doing next reconnect
 			wg.Wait()
+			t.disconnected()
 		}
 	}
 }
@@ -388,15 +436,25 @@ func (t *Ticker) triggerMessage(messageType int, message []byte) {
 }
 
 func (t *Ticker) triggerTick(tick Tick) {
+	t.ackSubscription(tick.InstrumentToken)
+
 	if t.callbacks.onTick != nil {
 		t.callbacks.onTick(tick)
 	}
+
+	if q := t.tickQueue(); q != nil {
+		q.push(tick)
+	}
 }
 
 func (t *Ticker) triggerOrderUpdate(order Order) {
 	if t.callbacks.onOrderUpdate != nil {
 		t.callbacks.onOrderUpdate(order)
 	}
+
+	if q := t.orderQueue(); q != nil {
+		q.push(order)
+	}
 }
 
 // Periodically check for last ping time and initiate reconnect if applicable.
@@ -467,6 +525,13 @@ func (t *Ticker) readMessage(ctx context.Context, wg *sync.WaitGroup) {
 
 // Close tries to close the connection gracefully. If the server doesn't close it
 func (t *Ticker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Conn == nil {
+		return nil
+	}
+
 	return t.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 }
 
@@ -477,26 +542,24 @@ func (t *Ticker) Stop() {
 	}
 }
 
-// Subscribe subscribes tick for the given list of tokens.
+// Subscribe subscribes tick for the given list of tokens. Tokens
+// subscribed before the ticker connects are sent once it does.
 func (t *Ticker) Subscribe(tokens []uint32) error {
 	if len(tokens) == 0 {
 		return nil
 	}
 
-	out, err := json.Marshal(tickerInput{
-		Type: "subscribe",
-		Val:  tokens,
-	})
-	if err != nil {
-		return err
-	}
-
+	t.mu.Lock()
 	// Store tokens to current subscriptions
 	for _, ts := range tokens {
-		t.subscribedTokens[ts] = modeEmpty
+		t.setSubscriptionMode(ts, modeEmpty)
 	}
 
-	return t.Conn.WriteMessage(websocket.TextMessage, out)
+	events, err := t.send("subscribe", tokens, tokens)
+	t.mu.Unlock()
+
+	t.triggerSubscriptions(events)
+	return err
 }
 
 // Unsubscribe unsubscribes tick for the given list of tokens.
@@ -505,6 +568,18 @@ func (t *Ticker) Unsubscribe(tokens []uint32) error {
 		return nil
 	}
 
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Remove tokens from current subscriptions
+	for _, ts := range tokens {
+		t.removeSubscription(ts)
+	}
+
+	if !t.connected {
+		return nil
+	}
+
 	out, err := json.Marshal(tickerInput{
 		Type: "unsubscribe",
 		Val:  tokens,
@@ -513,71 +588,72 @@ func (t *Ticker) Unsubscribe(tokens []uint32) error {
 		return err
 	}
 
-	// Remove tokens from current subscriptions
-	for _, ts := range tokens {
-		delete(t.subscribedTokens, ts)
-	}
-
 	return t.Conn.WriteMessage(websocket.TextMessage, out)
 }
 
-// SetMode changes mode for given list of tokens and mode.
+// SetMode changes mode for given list of tokens and mode. Modes set before
+// the ticker connects are sent once it does.
 func (t *Ticker) SetMode(mode Mode, tokens []uint32) error {
 	if len(tokens) == 0 {
 		return nil
 	}
 
-	out, err := json.Marshal(tickerInput{
-		Type: "mode",
-		Val:  []interface{}{mode, tokens},
-	})
-	if err != nil {
-		return err
-	}
-
+	t.mu.Lock()
 	// Set mode in current subscriptions stored
 	for _, ts := range tokens {
-		t.subscribedTokens[ts] = mode
+		t.setSubscriptionMode(ts, mode)
 	}
 
-	return t.Conn.WriteMessage(websocket.TextMessage, out)
+	events, err := t.send("mode", []interface{}{mode, tokens}, tokens)
+	t.mu.Unlock()
+
+	t.triggerSubscriptions(events)
+	return err
 }
 
 // Resubscribe resubscribes to the current stored subscriptions
 func (t *Ticker) Resubscribe() error {
+	t.mu.Lock()
 	var tokens []uint32
-	modes := map[Mode][]uint32{
-		ModeFull:  {},
-		ModeQuote: {},
-		ModeLTP:   {},
-	}
+	modes := map[Mode][]uint32{}
 
 	// Make a map of mode and corresponding tokens
-	for to, mo := range t.subscribedTokens {
+	for to, sub := range t.subscriptions {
 		tokens = append(tokens, to)
-		if mo != modeEmpty {
-			modes[mo] = append(modes[mo], to)
+		if sub.mode != modeEmpty {
+			modes[sub.mode] = append(modes[sub.mode], to)
 		}
 	}
-
-	fmt.Println("Subscribe again: ", tokens, t.subscribedTokens)
+	sortTokens(tokens)
 
 	// Subscribe to tokens
+	var events []SubscriptionStatus
 	if len(tokens) > 0 {
-		if err := t.Subscribe(tokens); err != nil {
+		ev, err := t.send("subscribe", tokens, tokens)
+		events = append(events, ev...)
+		if err != nil {
+			t.mu.Unlock()
+			t.triggerSubscriptions(events)
 			return err
 		}
 	}
 
 	// Set mode to tokens
-	for mo, tos := range modes {
-		if len(tos) > 0 {
-			if err := t.SetMode(mo, tos); err != nil {
+	for _, mo := range []Mode{ModeFull, ModeQuote, ModeLTP} {
+		if tos := modes[mo]; len(tos) > 0 {
+			sortTokens(tos)
+			ev, err := t.send("mode", []interface{}{mo, tos}, tos)
+			events = append(events, ev...)
+			if err != nil {
+				t.mu.Unlock()
+				t.triggerSubscriptions(events)
 				return err
 			}
 		}
 	}
+	t.mu.Unlock()
 
+	t.triggerSubscriptions(events)
 	return nil
 }
 
@@ -590,7 +666,9 @@ func (t *Ticker) processTextMessage(inp []byte) {
 
 	if msg.Type == messageError {
 		// Trigger text error
-		t.triggerError(fmt.Errorf(msg.Data.(string)))
+		err := fmt.Errorf("%v", msg.Data)
+		t.failSubscriptions(err)
+		t.triggerError(err)
 	} else if msg.Type == messageOrder {
 		// Parse order update data
 		order := struct {
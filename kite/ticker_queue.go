@@ -0,0 +1,337 @@
+package kite
+
+import "sync"
+
+// OverflowPolicy is what the Ticks and OrderUpdates channels do when their
+// buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the consumer to make room. Nothing is
+	// dropped, but reading the socket stalls meanwhile, which triggers a
+	// reconnect if it lasts longer than the data timeout.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered message.
+	OverflowDropOldest
+	// OverflowConflate replaces the buffered tick of the same instrument,
+	// or the buffered update of the same order, with the new one, so the
+	// consumer gets the latest of each. When the buffer is full of other
+	// instruments or orders, the oldest message is dropped.
+	OverflowConflate
+)
+
+// Default size of the buffers of the Ticks and OrderUpdates channels.
+const defaultTickerBuffer = 1024
+
+// queue is the buffer between the goroutine reading the socket and a
+// consumer channel, which applies the overflow policy. It is fed by push and
+// drained into the channel by pump.
+type queue struct {
+	size   int
+	policy OverflowPolicy
+	// key returns the key messages are conflated by.
+	key func(interface{}) interface{}
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   []interface{}
+	head    int
+	keys    map[interface{}]int
+	closed  bool
+	dropped uint64
+	// stop is closed along with the queue, to release a pump blocked on a
+	// consumer which stopped receiving.
+	stop chan struct{}
+}
+
+func newQueue(size int, policy OverflowPolicy, key func(interface{}) interface{}) *queue {
+	if size <= 0 {
+		size = defaultTickerBuffer
+	}
+
+	q := &queue{
+		size:   size,
+		policy: policy,
+		key:    key,
+		keys:   make(map[interface{}]int),
+		stop:   make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// push adds a message, applying the overflow policy if the queue is full.
+// Messages pushed after close are discarded.
+func (q *queue) push(v interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.policy == OverflowConflate {
+		if pos, ok := q.keys[q.key(v)]; ok {
+			q.items[pos-q.head] = v
+			q.dropped++
+			return
+		}
+	}
+
+	for len(q.items) >= q.size && !q.closed {
+		if q.policy != OverflowBlock {
+			q.popLocked()
+			q.dropped++
+			break
+		}
+
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		return
+	}
+
+	if q.policy == OverflowConflate {
+		q.keys[q.key(v)] = q.head + len(q.items)
+	}
+	q.items = append(q.items, v)
+	q.cond.Broadcast()
+}
+
+// pop removes the oldest message, waiting for one if the queue is empty. It
+// returns false once the queue is closed and empty.
+func (q *queue) pop() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 {
+		if q.closed {
+			return nil, false
+		}
+
+		q.cond.Wait()
+	}
+
+	v := q.popLocked()
+	q.cond.Broadcast()
+
+	return v, true
+}
+
+func (q *queue) popLocked() interface{} {
+	v := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+
+	if q.policy == OverflowConflate {
+		k := q.key(v)
+		if q.keys[k] == q.head {
+			delete(q.keys, k)
+		}
+	}
+	q.head++
+
+	return v
+}
+
+// close stops the queue. Messages already buffered are still popped, while
+// blocked and later pushes are discarded.
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	q.closed = true
+	close(q.stop)
+	q.cond.Broadcast()
+}
+
+// droppedCount returns the number of messages dropped or conflated.
+func (q *queue) droppedCount() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// pump sends the queued messages to send until the queue is closed and
+// drained, then calls done. send is passed a channel closed along with the
+// queue, and returns false if it gave up on the message because of it, in
+// which case the rest of the messages are discarded.
+func (q *queue) pump(send func(v interface{}, stop <-chan struct{}) bool, done func()) {
+	defer done()
+
+	for {
+		v, ok := q.pop()
+		if !ok || !send(v, q.stop) {
+			return
+		}
+	}
+}
+
+// tickerQueues holds the queues of the Ticks and OrderUpdates channels,
+// which are created on first use and closed when Serve returns, so that
+// each Serve has channels of its own.
+type tickerQueues struct {
+	mu          sync.Mutex
+	tickSize    int
+	tickPolicy  OverflowPolicy
+	orderSize   int
+	orderPolicy OverflowPolicy
+	ticks       *queue
+	tickCh      chan Tick
+	orders      *queue
+	orderCh     chan Order
+	// gen counts the Serve calls which have returned, so a Serve only
+	// closes the channels it fed.
+	gen uint64
+}
+
+// SetTickBuffer sets the number of ticks the Ticks channel buffers and what
+// happens when the consumer falls behind by more. It takes effect for the
+// channels returned by later calls to Ticks. The default is a buffer of
+// 1024 ticks with OverflowBlock.
+func (t *Ticker) SetTickBuffer(size int, policy OverflowPolicy) {
+	t.queues.mu.Lock()
+	defer t.queues.mu.Unlock()
+	t.queues.tickSize, t.queues.tickPolicy = size, policy
+}
+
+// SetOrderUpdateBuffer sets the number of order updates the OrderUpdates
+// channel buffers and what happens when the consumer falls behind by more.
+// It takes effect for the channels returned by later calls to OrderUpdates.
+// The default is a buffer of 1024 updates with OverflowBlock.
+func (t *Ticker) SetOrderUpdateBuffer(size int, policy OverflowPolicy) {
+	t.queues.mu.Lock()
+	defer t.queues.mu.Unlock()
+	t.queues.orderSize, t.queues.orderPolicy = size, policy
+}
+
+// Ticks returns the channel ticks are sent on, in addition to the OnTick
+// callback. Ticks are buffered as set by SetTickBuffer, so a slow consumer
+// doesn't stall reading the socket. The channel is closed when the ticker
+// is stopped or Serve returns, and buffered ticks which aren't received by
+// then may be discarded. Each Serve has a channel of its own: call Ticks
+// again for the ticks of the next one.
+//
+//	go ticker.Serve()
+//	for tick := range ticker.Ticks() {
+//		...
+//	}
+func (t *Ticker) Ticks() <-chan Tick {
+	q := &t.queues
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.tickCh == nil {
+		q.tickCh = make(chan Tick)
+		q.ticks = newQueue(q.tickSize, q.tickPolicy, func(v interface{}) interface{} {
+			return v.(Tick).InstrumentToken
+		})
+
+		ch := q.tickCh
+		go q.ticks.pump(func(v interface{}, stop <-chan struct{}) bool {
+			select {
+			case ch <- v.(Tick):
+				return true
+			case <-stop:
+				return false
+			}
+		}, func() { close(ch) })
+	}
+
+	return q.tickCh
+}
+
+// OrderUpdates returns the channel order updates are sent on, in addition
+// to the OnOrderUpdate callback. Updates are buffered as set by
+// SetOrderUpdateBuffer. Like Ticks, the channel is closed when the ticker
+// is stopped or Serve returns, and each Serve has a channel of its own.
+func (t *Ticker) OrderUpdates() <-chan Order {
+	q := &t.queues
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.orderCh == nil {
+		q.orderCh = make(chan Order)
+		q.orders = newQueue(q.orderSize, q.orderPolicy, func(v interface{}) interface{} {
+			return v.(Order).OrderID
+		})
+
+		ch := q.orderCh
+		go q.orders.pump(func(v interface{}, stop <-chan struct{}) bool {
+			select {
+			case ch <- v.(Order):
+				return true
+			case <-stop:
+				return false
+			}
+		}, func() { close(ch) })
+	}
+
+	return q.orderCh
+}
+
+// DroppedTicks returns the number of ticks dropped or conflated because the
+// consumer of the current Ticks channel fell behind.
+func (t *Ticker) DroppedTicks() uint64 {
+	if q := t.tickQueue(); q != nil {
+		return q.droppedCount()
+	}
+
+	return 0
+}
+
+// DroppedOrderUpdates returns the number of order updates dropped or
+// conflated because the consumer of the current OrderUpdates channel fell
+// behind.
+func (t *Ticker) DroppedOrderUpdates() uint64 {
+	if q := t.orderQueue(); q != nil {
+		return q.droppedCount()
+	}
+
+	return 0
+}
+
+func (t *Ticker) tickQueue() *queue {
+	t.queues.mu.Lock()
+	defer t.queues.mu.Unlock()
+	return t.queues.ticks
+}
+
+func (t *Ticker) orderQueue() *queue {
+	t.queues.mu.Lock()
+	defer t.queues.mu.Unlock()
+	return t.queues.orders
+}
+
+// queueGen returns the generation of the channels fed by a Serve starting
+// now.
+func (t *Ticker) queueGen() uint64 {
+	t.queues.mu.Lock()
+	defer t.queues.mu.Unlock()
+	return t.queues.gen
+}
+
+// closeQueues closes the Ticks and OrderUpdates channels of generation gen,
+// so that later calls to Ticks and OrderUpdates return new channels.
+func (t *Ticker) closeQueues(gen uint64) {
+	q := &t.queues
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if gen != q.gen {
+		return
+	}
+	q.gen++
+
+	if q.ticks != nil {
+		q.ticks.close()
+	}
+
+	if q.orders != nil {
+		q.orders.close()
+	}
+
+	q.ticks, q.tickCh, q.orders, q.orderCh = nil, nil, nil, nil
+}
@@ -0,0 +1,251 @@
+package kite
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func tokenKey(v interface{}) interface{} { return v.(Tick).InstrumentToken }
+
+// drain pops the buffered messages of a closed queue.
+func drain(q *queue) []uint32 {
+	q.close()
+	var tokens []uint32
+	for {
+		v, ok := q.pop()
+		if !ok {
+			return tokens
+		}
+		tokens = append(tokens, v.(Tick).InstrumentToken)
+	}
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	t.Parallel()
+	q := newQueue(3, OverflowDropOldest, tokenKey)
+	for i := uint32(1); i <= 5; i++ {
+		q.push(Tick{InstrumentToken: i})
+	}
+
+	require.Equal(t, uint64(2), q.droppedCount())
+	require.Equal(t, []uint32{3, 4, 5}, drain(q))
+
+	// Pushes after close are discarded.
+	q.push(Tick{InstrumentToken: 6})
+	_, ok := q.pop()
+	require.False(t, ok)
+}
+
+func TestQueueConflate(t *testing.T) {
+	t.Parallel()
+	q := newQueue(3, OverflowConflate, tokenKey)
+	q.push(Tick{InstrumentToken: 1, LastPrice: 1})
+	q.push(Tick{InstrumentToken: 2, LastPrice: 1})
+	q.push(Tick{InstrumentToken: 1, LastPrice: 2})
+	require.Equal(t, uint64(1), q.droppedCount())
+
+	// The tick keeps its place in the queue.
+	v, ok := q.pop()
+	require.True(t, ok)
+	require.Equal(t, Tick{InstrumentToken: 1, LastPrice: 2}, v)
+
+	// A token popped is queued again.
+	q.push(Tick{InstrumentToken: 1, LastPrice: 3})
+	q.push(Tick{InstrumentToken: 3})
+	q.push(Tick{InstrumentToken: 2, LastPrice: 2})
+	require.Equal(t, uint64(2), q.droppedCount())
+
+	// When full of other tokens the oldest is dropped.
+	q.push(Tick{InstrumentToken: 4})
+	require.Equal(t, uint64(3), q.droppedCount())
+	require.Equal(t, []uint32{1, 3, 4}, drain(q))
+}
+
+func TestQueueBlock(t *testing.T) {
+	t.Parallel()
+	q := newQueue(1, OverflowBlock, tokenKey)
+	q.push(Tick{InstrumentToken: 1})
+
+	pushed := make(chan struct{})
+	go func() {
+		q.push(Tick{InstrumentToken: 2})
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push didn't block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	v, _ := q.pop()
+	require.Equal(t, uint32(1), v.(Tick).InstrumentToken)
+	<-pushed
+	require.Equal(t, uint64(0), q.droppedCount())
+
+	// Closing releases a blocked push, whose tick is discarded.
+	blocked := make(chan struct{})
+	go func() {
+		q.push(Tick{InstrumentToken: 3})
+		close(blocked)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, []uint32{2}, drain(q))
+	<-blocked
+}
+
+func TestQueuePumpStops(t *testing.T) {
+	t.Parallel()
+	q := newQueue(10, OverflowBlock, tokenKey)
+	ch := make(chan Tick)
+	exited := make(chan struct{})
+	go q.pump(func(v interface{}, stop <-chan struct{}) bool {
+		select {
+		case ch <- v.(Tick):
+			return true
+		case <-stop:
+			return false
+		}
+	}, func() { close(exited) })
+
+	q.push(Tick{InstrumentToken: 1})
+	q.push(Tick{InstrumentToken: 2})
+	require.Equal(t, uint32(1), (<-ch).InstrumentToken)
+
+	// The consumer stops receiving, which doesn't keep the pump blocked
+	// once the queue is closed.
+	q.close()
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("pump is blocked on a consumer which stopped receiving")
+	}
+}
+
+// ltpPacket returns a binary message of LTP mode ticks for tokens.
+func ltpPacket(tokens ...uint32) []byte {
+	b := make([]byte, 2, 2+len(tokens)*10)
+	binary.BigEndian.PutUint16(b, uint16(len(tokens)))
+	for _, tk := range tokens {
+		p := make([]byte, 10)
+		binary.BigEndian.PutUint16(p[0:], modeLTPLength)
+		binary.BigEndian.PutUint32(p[2:], tk)
+		binary.BigEndian.PutUint32(p[6:], 10050)
+		b = append(b, p...)
+	}
+
+	return b
+}
+
+// tickerServer serves a websocket which sends msgs, then waits for the
+// client to go away.
+func tickerServer(msgs func(*websocket.Conn)) (*httptest.Server, url.URL) {
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		msgs(conn)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return ts, url.URL{Scheme: "ws", Host: strings.TrimPrefix(ts.URL, "http://")}
+}
+
+func TestTickerChannels(t *testing.T) {
+	t.Parallel()
+	order, err := json.Marshal(map[string]interface{}{
+		"type": "order",
+		"data": map[string]interface{}{"order_id": "151220000000000", "status": "COMPLETE"},
+	})
+	require.Nil(t, err)
+
+	ts, u := tickerServer(func(conn *websocket.Conn) {
+		conn.WriteMessage(websocket.BinaryMessage, ltpPacket(256265, 260105))
+		conn.WriteMessage(websocket.TextMessage, order)
+		conn.WriteMessage(websocket.BinaryMessage, ltpPacket(256265))
+	})
+	defer ts.Close()
+
+	ticker := NewTicker("token")
+	ticker.SetRootURL(u)
+	ticker.SetAutoReconnect(false)
+	var callbacks int
+	ticker.OnTick(func(Tick) { callbacks++ })
+
+	ticks, orders := ticker.Ticks(), ticker.OrderUpdates()
+	go ticker.Serve()
+
+	var got []uint32
+	for len(got) < 3 {
+		tick := <-ticks
+		require.Equal(t, 100.5, tick.LastPrice)
+		got = append(got, tick.InstrumentToken)
+	}
+	require.Equal(t, []uint32{256265, 260105, 256265}, got)
+
+	o := <-orders
+	require.Equal(t, "151220000000000", o.OrderID)
+	require.Equal(t, "COMPLETE", o.Status)
+	require.Equal(t, 3, callbacks)
+	require.Equal(t, uint64(0), ticker.DroppedTicks())
+
+	// Stopping closes the channels.
+	ticker.Stop()
+	_, ok := <-ticks
+	require.False(t, ok)
+	_, ok = <-orders
+	require.False(t, ok)
+	ticker.Conn.Close()
+}
+
+func TestTickerChannelsPerServe(t *testing.T) {
+	t.Parallel()
+	ts, u := tickerServer(func(conn *websocket.Conn) {
+		conn.WriteMessage(websocket.BinaryMessage, ltpPacket(256265))
+		conn.WriteMessage(websocket.BinaryMessage, ltpPacket(260105))
+	})
+	defer ts.Close()
+
+	ticker := NewTicker("token")
+	ticker.SetRootURL(u)
+	ticker.SetAutoReconnect(false)
+
+	for i := 0; i < 2; i++ {
+		ticks := ticker.Ticks()
+		served := make(chan struct{})
+		go func() {
+			ticker.Serve()
+			close(served)
+		}()
+
+		require.Equal(t, uint32(256265), (<-ticks).InstrumentToken)
+
+		// The second tick is never received, which doesn't keep the
+		// channel from being closed.
+		time.Sleep(20 * time.Millisecond)
+		ticker.Stop()
+		ticker.Conn.Close()
+		<-served
+
+		for range ticks {
+		}
+		require.Equal(t, uint64(0), ticker.DroppedTicks())
+	}
+}
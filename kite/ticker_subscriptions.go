@@ -0,0 +1,231 @@
+package kite
+
+import (
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// SubscriptionState is the state of the subscription of a token.
+type SubscriptionState string
+
+const (
+	// SubscriptionPending is a subscription waiting for the ticker to
+	// connect.
+	SubscriptionPending SubscriptionState = "pending"
+	// SubscriptionSent is a subscription sent to the server, which hasn't
+	// ticked yet.
+	SubscriptionSent SubscriptionState = "sent"
+	// SubscriptionActive is a subscription which has ticked, which the
+	// server acknowledges subscriptions by.
+	SubscriptionActive SubscriptionState = "active"
+	// SubscriptionFailed is a subscription which couldn't be sent, or
+	// which the server sent an error for in response to the request it was
+	// last sent in, before it ticked.
+	SubscriptionFailed SubscriptionState = "failed"
+)
+
+// SubscriptionStatus is the state of the subscription of a token.
+type SubscriptionStatus struct {
+	Token uint32
+	// Mode is the mode set for the token, or empty if none was.
+	Mode  Mode
+	State SubscriptionState
+	// Err is why a failed subscription failed.
+	Err error
+}
+
+type subscription struct {
+	mode  Mode
+	state SubscriptionState
+	err   error
+}
+
+func (s *subscription) status(token uint32) SubscriptionStatus {
+	st := SubscriptionStatus{Token: token, Mode: s.mode, State: s.state, Err: s.err}
+	if st.Mode == modeEmpty {
+		st.Mode = ""
+	}
+
+	return st
+}
+
+// OnSubscription callback, called whenever the state of the subscription of
+// a token changes.
+func (t *Ticker) OnSubscription(f func(status SubscriptionStatus)) {
+	t.callbacks.onSubscription = f
+}
+
+// Subscriptions returns the state of the subscriptions of all the tokens
+// subscribed to, ordered by token.
+func (t *Ticker) Subscriptions() []SubscriptionStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]SubscriptionStatus, 0, len(t.subscriptions))
+	for token, sub := range t.subscriptions {
+		out = append(out, sub.status(token))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Token < out[j].Token })
+
+	return out
+}
+
+// Subscription returns the state of the subscription of a token.
+func (t *Ticker) Subscription(token uint32) (SubscriptionStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sub, ok := t.subscriptions[token]
+	if !ok {
+		return SubscriptionStatus{}, false
+	}
+
+	return sub.status(token), true
+}
+
+// setSubscriptionMode sets the mode of the subscription of token, adding
+// the subscription if there is none. t.mu must be held.
+func (t *Ticker) setSubscriptionMode(token uint32, mode Mode) {
+	if sub, ok := t.subscriptions[token]; ok {
+		sub.mode = mode
+		return
+	}
+
+	t.subscriptions[token] = &subscription{mode: mode}
+	atomic.AddInt32(&t.unacked, 1)
+}
+
+// removeSubscription forgets the subscription of token. t.mu must be held.
+func (t *Ticker) removeSubscription(token uint32) {
+	sub, ok := t.subscriptions[token]
+	if !ok {
+		return
+	}
+
+	if sub.state != SubscriptionActive {
+		atomic.AddInt32(&t.unacked, -1)
+	}
+	delete(t.subscriptions, token)
+}
+
+// send sends a subscribe or mode request for tokens if the ticker is
+// connected, or leaves them pending until it does. It returns the changes
+// of the tokens' states. t.mu must be held.
+func (t *Ticker) send(typ string, val interface{}, tokens []uint32) ([]SubscriptionStatus, error) {
+	if !t.connected {
+		return t.setStates(tokens, SubscriptionPending, nil), nil
+	}
+
+	out, err := json.Marshal(tickerInput{
+		Type: typ,
+		Val:  val,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.Conn.WriteMessage(websocket.TextMessage, out); err != nil {
+		return t.setStates(tokens, SubscriptionFailed, err), err
+	}
+
+	t.lastRequest = append(t.lastRequest[:0], tokens...)
+	return t.setStates(tokens, SubscriptionSent, nil), nil
+}
+
+// setStates sets the state of the subscriptions of tokens, except that a
+// mode request doesn't make an active subscription sent again. It returns
+// the subscriptions which changed. t.mu must be held.
+func (t *Ticker) setStates(tokens []uint32, state SubscriptionState, err error) []SubscriptionStatus {
+	var changed []SubscriptionStatus
+	for _, token := range tokens {
+		sub, ok := t.subscriptions[token]
+		if !ok || (state == SubscriptionSent && sub.state == SubscriptionActive) {
+			continue
+		}
+
+		if sub.state == state && sub.err == err {
+			continue
+		}
+
+		if sub.state == SubscriptionActive {
+			atomic.AddInt32(&t.unacked, 1)
+		} else if state == SubscriptionActive {
+			atomic.AddInt32(&t.unacked, -1)
+		}
+
+		sub.state, sub.err = state, err
+		changed = append(changed, sub.status(token))
+	}
+
+	return changed
+}
+
+// ackSubscription marks the subscription of a token which ticked active.
+// Once all the subscriptions are active it returns without locking t.mu.
+func (t *Ticker) ackSubscription(token uint32) {
+	if atomic.LoadInt32(&t.unacked) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	var events []SubscriptionStatus
+	if sub, ok := t.subscriptions[token]; ok && sub.state != SubscriptionActive {
+		events = t.setStates([]uint32{token}, SubscriptionActive, nil)
+	}
+	t.mu.Unlock()
+
+	t.triggerSubscriptions(events)
+}
+
+// failSubscriptions marks the subscriptions of the last request sent which
+// haven't ticked failed with a server error. The server doesn't say which
+// tokens an error is about, so subscriptions of earlier requests are left
+// sent, and a token which ticks afterwards is marked active again.
+func (t *Ticker) failSubscriptions(err error) {
+	t.mu.Lock()
+	var tokens []uint32
+	for _, token := range t.lastRequest {
+		if sub, ok := t.subscriptions[token]; ok && sub.state == SubscriptionSent {
+			tokens = append(tokens, token)
+		}
+	}
+	events := t.setStates(tokens, SubscriptionFailed, err)
+	t.mu.Unlock()
+
+	t.triggerSubscriptions(events)
+}
+
+// disconnected marks the subscriptions pending until the ticker
+// reconnects.
+func (t *Ticker) disconnected() {
+	t.mu.Lock()
+	t.connected = false
+	t.lastRequest = nil
+
+	tokens := make([]uint32, 0, len(t.subscriptions))
+	for token := range t.subscriptions {
+		tokens = append(tokens, token)
+	}
+	sortTokens(tokens)
+	events := t.setStates(tokens, SubscriptionPending, nil)
+	t.mu.Unlock()
+
+	t.triggerSubscriptions(events)
+}
+
+func (t *Ticker) triggerSubscriptions(events []SubscriptionStatus) {
+	if t.callbacks.onSubscription == nil {
+		return
+	}
+
+	for _, e := range events {
+		t.callbacks.onSubscription(e)
+	}
+}
+
+func sortTokens(tokens []uint32) {
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i] < tokens[j] })
+}
@@ -0,0 +1,176 @@
+package kite
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickerPendingSubscriptions(t *testing.T) {
+	t.Parallel()
+	requests := make(chan tickerInput, 10)
+	ts, u := tickerServer(func(conn *websocket.Conn) {
+		read := func(n int) bool {
+			for i := 0; i < n; i++ {
+				var in tickerInput
+				if err := conn.ReadJSON(&in); err != nil {
+					return false
+				}
+				requests <- in
+			}
+			return true
+		}
+
+		if !read(2) {
+			return
+		}
+		conn.WriteMessage(websocket.BinaryMessage, ltpPacket(256265))
+
+		if !read(1) {
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "error", "data": "Invalid token"}`))
+	})
+	defer ts.Close()
+
+	ticker := NewTicker("token")
+	ticker.SetRootURL(u)
+	ticker.SetAutoReconnect(false)
+
+	events := make(chan SubscriptionStatus, 20)
+	ticker.OnSubscription(func(s SubscriptionStatus) { events <- s })
+	errs := make(chan error, 1)
+	ticker.OnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	// Subscribing before connecting doesn't panic but queues.
+	require.Nil(t, ticker.Subscribe([]uint32{260105, 256265}))
+	require.Nil(t, ticker.SetMode(ModeFull, []uint32{256265}))
+	require.Equal(t, []SubscriptionStatus{
+		{Token: 256265, Mode: ModeFull, State: SubscriptionPending},
+		{Token: 260105, State: SubscriptionPending},
+	}, ticker.Subscriptions())
+	require.Equal(t, SubscriptionStatus{Token: 260105, State: SubscriptionPending}, <-events)
+	require.Equal(t, SubscriptionStatus{Token: 256265, State: SubscriptionPending}, <-events)
+
+	// Unsubscribed tokens are forgotten without being sent.
+	require.Nil(t, ticker.Subscribe([]uint32{1}))
+	<-events
+	require.Nil(t, ticker.Unsubscribe([]uint32{1}))
+	_, ok := ticker.Subscription(1)
+	require.False(t, ok)
+
+	go ticker.Serve()
+	defer ticker.Stop()
+
+	// The queued subscriptions and modes are flushed on connect.
+	sub := <-requests
+	require.Equal(t, "subscribe", sub.Type)
+	require.Equal(t, []interface{}{256265.0, 260105.0}, sub.Val)
+	mode := <-requests
+	require.Equal(t, "mode", mode.Type)
+	require.Equal(t, []interface{}{"full", []interface{}{256265.0}}, mode.Val)
+
+	require.Equal(t, SubscriptionSent, (<-events).State)
+	require.Equal(t, SubscriptionSent, (<-events).State)
+
+	// The token which ticks is acknowledged.
+	require.Equal(t, SubscriptionStatus{Token: 256265, Mode: ModeFull, State: SubscriptionActive}, <-events)
+	require.Equal(t, int32(1), atomic.LoadInt32(&ticker.unacked))
+
+	// The server error fails the token of the last request only.
+	require.Nil(t, ticker.Subscribe([]uint32{408065}))
+	require.Equal(t, SubscriptionStatus{Token: 408065, State: SubscriptionSent}, <-events)
+	require.Equal(t, "subscribe", (<-requests).Type)
+	failed := <-events
+	require.Equal(t, uint32(408065), failed.Token)
+	require.Equal(t, SubscriptionFailed, failed.State)
+	require.EqualError(t, failed.Err, "Invalid token")
+	require.EqualError(t, <-errs, "Invalid token")
+
+	for token, state := range map[uint32]SubscriptionState{256265: SubscriptionActive, 260105: SubscriptionSent, 408065: SubscriptionFailed} {
+		st, ok := ticker.Subscription(token)
+		require.True(t, ok)
+		require.Equal(t, state, st.State)
+	}
+
+	require.Nil(t, ticker.Unsubscribe([]uint32{260105, 408065}))
+	require.Equal(t, int32(0), atomic.LoadInt32(&ticker.unacked))
+}
+
+func TestTickerConcurrentSubscriptions(t *testing.T) {
+	t.Parallel()
+	received := make(chan int, 1)
+	ts, u := tickerServer(func(conn *websocket.Conn) {
+		n := 0
+		for n < 200 {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			n++
+		}
+		received <- n
+	})
+	defer ts.Close()
+
+	ticker := NewTicker("token")
+	ticker.SetRootURL(u)
+	ticker.SetAutoReconnect(false)
+	connected := make(chan struct{})
+	ticker.OnConnect(func() { close(connected) })
+	go ticker.Serve()
+	defer ticker.Stop()
+	<-connected
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				token := uint32(i*100 + j)
+				require.Nil(t, ticker.Subscribe([]uint32{token}))
+				require.Nil(t, ticker.SetMode(ModeQuote, []uint32{token}))
+				ticker.Subscriptions()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 200, <-received)
+	subs := ticker.Subscriptions()
+	require.Equal(t, 100, len(subs))
+	for _, s := range subs {
+		require.Equal(t, ModeQuote, s.Mode)
+		require.Equal(t, SubscriptionSent, s.State)
+	}
+}
+
+func TestSubscriptionStates(t *testing.T) {
+	t.Parallel()
+	ticker := NewTicker("token")
+	ticker.subscriptions[1] = &subscription{mode: modeEmpty, state: SubscriptionActive}
+	ticker.subscriptions[2] = &subscription{mode: ModeLTP, state: SubscriptionPending}
+
+	// A mode request doesn't make an active subscription sent again.
+	changed := ticker.setStates([]uint32{1, 2, 3}, SubscriptionSent, nil)
+	require.Equal(t, []SubscriptionStatus{{Token: 2, Mode: ModeLTP, State: SubscriptionSent}}, changed)
+
+	err := errors.New("write failed")
+	changed = ticker.setStates([]uint32{1}, SubscriptionFailed, err)
+	require.Equal(t, []SubscriptionStatus{{Token: 1, State: SubscriptionFailed, Err: err}}, changed)
+	require.Nil(t, ticker.setStates([]uint32{1}, SubscriptionFailed, err))
+
+	b, jerr := json.Marshal(tickerInput{Type: "mode", Val: []interface{}{ModeLTP, []uint32{2}}})
+	require.Nil(t, jerr)
+	require.Equal(t, `{"a":"mode","v":["ltp",[2]]}`, string(b))
+}
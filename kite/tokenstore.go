@@ -0,0 +1,193 @@
+package kite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Kite sessions expire every day at 6 AM IST.
+const tokenExpiryHour = 6
+
+// Token is a session token along with its expiry.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// NewToken returns a token for accessToken issued now.
+func NewToken(accessToken string) Token {
+	return Token{
+		AccessToken: accessToken,
+		ExpiresAt:   TokenExpiry(time.Now()),
+	}
+}
+
+// Expired reports whether the token has expired at time now. Tokens
+// without an expiry never expire.
+func (t Token) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// TokenExpiry returns the expiry of a session issued at t, which is the
+// next 6 AM IST.
+func TokenExpiry(t time.Time) time.Time {
+	loc := istLocation()
+	t = t.In(loc)
+
+	exp := time.Date(t.Year(), t.Month(), t.Day(), tokenExpiryHour, 0, 0, 0, loc)
+	if !t.Before(exp) {
+		exp = exp.AddDate(0, 0, 1)
+	}
+
+	return exp
+}
+
+// istLocation returns the Asia/Kolkata location, falling back to a fixed
+// +05:30 zone when the tz database isn't available.
+func istLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+
+	return loc
+}
+
+// TokenStore stores session tokens so that they can be shared between
+// clients, tickers and processes.
+type TokenStore interface {
+	// Load returns the stored token. An empty token is returned if
+	// nothing has been stored yet.
+	Load() (Token, error)
+	// Save stores the token, replacing the previous one.
+	Save(Token) error
+}
+
+// MemoryTokenStore is a TokenStore which keeps the token in memory.
+type MemoryTokenStore struct {
+	mu    sync.RWMutex
+	token Token
+}
+
+// NewMemoryTokenStore returns a new in-memory token store holding t.
+func NewMemoryTokenStore(t Token) *MemoryTokenStore {
+	return &MemoryTokenStore{token: t}
+}
+
+// Load returns the stored token.
+func (s *MemoryTokenStore) Load() (Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token, nil
+}
+
+// Save stores the token.
+func (s *MemoryTokenStore) Save(t Token) error {
+	s.mu.Lock()
+	s.token = t
+	s.mu.Unlock()
+	return nil
+}
+
+// FileTokenStore is a TokenStore which persists the token as JSON in a file
+// readable only by its owner. Writes are atomic, so concurrent readers never
+// see a partially written file. The file is only re-read when it changes.
+type FileTokenStore struct {
+	path string
+
+	mu      sync.Mutex
+	token   Token
+	modTime time.Time
+	size    int64
+}
+
+// NewFileTokenStore returns a token store persisting to path.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load returns the token stored in the file.
+func (s *FileTokenStore) Load() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fi, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return Token{}, nil
+	} else if err != nil {
+		return Token{}, err
+	}
+
+	if fi.ModTime().Equal(s.modTime) && fi.Size() == s.size {
+		return s.token, nil
+	}
+
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return Token{}, err
+	}
+
+	var t Token
+	if err := json.Unmarshal(b, &t); err != nil {
+		return Token{}, err
+	}
+
+	s.token, s.modTime, s.size = t, fi.ModTime(), fi.Size()
+	return t, nil
+}
+
+// Save writes the token to a temporary file and renames it over the store.
+func (s *FileTokenStore) Save(t Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	// TempFile creates files with 0600 permissions already, but be explicit
+	// as the token is a credential.
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Force a re-read on the next Load.
+	s.modTime = time.Time{}
+	return nil
+}
@@ -0,0 +1,222 @@
+package kite
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	t.Parallel()
+	ist := istLocation()
+	tests := []struct {
+		issued time.Time
+		want   time.Time
+	}{
+		{time.Date(2023, 7, 14, 9, 30, 0, 0, ist), time.Date(2023, 7, 15, 6, 0, 0, 0, ist)},
+		{time.Date(2023, 7, 14, 5, 59, 0, 0, ist), time.Date(2023, 7, 14, 6, 0, 0, 0, ist)},
+		{time.Date(2023, 7, 14, 6, 0, 0, 0, ist), time.Date(2023, 7, 15, 6, 0, 0, 0, ist)},
+		// 23:00 UTC is 04:30 IST on the next day.
+		{time.Date(2023, 7, 14, 23, 0, 0, 0, time.UTC), time.Date(2023, 7, 15, 6, 0, 0, 0, ist)},
+	}
+
+	for _, tt := range tests {
+		require.True(t, tt.want.Equal(TokenExpiry(tt.issued)), "issued %v, got %v", tt.issued, TokenExpiry(tt.issued))
+	}
+
+	tok := Token{AccessToken: "a", ExpiresAt: tests[0].want}
+	require.False(t, tok.Expired(tests[0].issued))
+	require.True(t, tok.Expired(tests[0].want))
+	require.False(t, Token{AccessToken: "a"}.Expired(time.Now()))
+}
+
+func TestFileTokenStore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kite")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token.json")
+	s := NewFileTokenStore(path)
+
+	tok, err := s.Load()
+	require.Nil(t, err)
+	require.Equal(t, "", tok.AccessToken)
+
+	require.Nil(t, s.Save(Token{AccessToken: "a", RefreshToken: "r"}))
+	fi, err := os.Stat(path)
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// A second store, like another process, sees the same token.
+	tok, err = NewFileTokenStore(path).Load()
+	require.Nil(t, err)
+	require.Equal(t, "a", tok.AccessToken)
+	require.Equal(t, "r", tok.RefreshToken)
+
+	require.Nil(t, NewFileTokenStore(path).Save(Token{AccessToken: "b"}))
+	tok, err = s.Load()
+	require.Nil(t, err)
+	require.Equal(t, "b", tok.AccessToken)
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*"))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(files))
+}
+
+func TestClientTokenStore(t *testing.T) {
+	t.Parallel()
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if auth == "enctoken expired" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"status":"error","error_type":"TokenException","message":"Incorrect api_key or access_token."}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer ts.Close()
+
+	store := NewMemoryTokenStore(NewToken("expired"))
+	var expired []Error
+
+	kc := New("")
+	kc.SetBaseURI(ts.URL)
+	kc.SetTokenStore(store)
+	kc.OnTokenExpired(func(err Error) {
+		expired = append(expired, err)
+	})
+
+	_, err := kc.GetOrders()
+	require.Error(t, err)
+	require.Equal(t, 1, len(expired))
+	require.Equal(t, TokenError, expired[0].ErrorType)
+
+	// A token refreshed elsewhere is picked up on the next request.
+	store.Save(NewToken("fresh"))
+	_, err = kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, "enctoken fresh", auth)
+	require.Equal(t, 1, len(expired))
+
+	kc.SetAccessToken("newer")
+	tok, _ := store.Load()
+	require.Equal(t, "newer", tok.AccessToken)
+	require.False(t, tok.ExpiresAt.IsZero())
+}
+
+type failingTokenStore struct{ MemoryTokenStore }
+
+func (s *failingTokenStore) Save(Token) error { return errors.New("disk full") }
+
+func TestClientTokenStoreSaveError(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"access_token":"new","refresh_token":"r"}}`))
+	}))
+	defer ts.Close()
+
+	kc := New("")
+	kc.SetBaseURI(ts.URL)
+	kc.SetTokenStore(&failingTokenStore{})
+
+	session, err := kc.GenerateSession("request", "secret")
+	require.Error(t, err)
+	require.Equal(t, "new", session.AccessToken)
+	require.EqualError(t, errors.Unwrap(err), "disk full")
+
+	_, err = kc.RenewAccessToken("r", "secret")
+	require.Error(t, err)
+}
+
+func TestClientExpiredStoredToken(t *testing.T) {
+	t.Parallel()
+	var (
+		auth string
+		hits int32
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer ts.Close()
+
+	store := NewMemoryTokenStore(Token{AccessToken: "dead", ExpiresAt: time.Now().Add(-time.Minute)})
+	var expired []Error
+
+	kc := New("dead")
+	kc.SetBaseURI(ts.URL)
+	kc.SetTokenStore(store)
+	kc.OnTokenExpired(func(err Error) { expired = append(expired, err) })
+
+	// Requests fail without being sent, and the callback is fired once for
+	// the expired token.
+	for i := 0; i < 3; i++ {
+		_, err := kc.GetOrders()
+		require.True(t, errors.Is(err, ErrTokenExpired))
+		require.Equal(t, URIGetOrders, err.(Error).Path)
+	}
+	require.Equal(t, int32(0), atomic.LoadInt32(&hits))
+	require.Equal(t, 1, len(expired))
+	require.Equal(t, TokenError, expired[0].ErrorType)
+
+	// Logging in again is still sent.
+	kc.GenerateSession("request-token", "secret")
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	require.Equal(t, "", auth)
+
+	// A token set on the client since is used instead.
+	kc.setAccessToken("fresh")
+	_, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, "enctoken fresh", auth)
+	require.Equal(t, 1, len(expired))
+}
+
+func TestClientSessionConcurrentRequests(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == URIUserSession {
+			w.Write([]byte(`{"status":"success","data":{"access_token":"new","refresh_token":"refresh"}}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer ts.Close()
+
+	kc := New("key")
+	kc.SetBaseURI(ts.URL)
+	kc.SetRateLimiter(nil)
+	kc.SetTokenStore(NewMemoryTokenStore(Token{}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := kc.GetOrders()
+				require.Nil(t, err)
+			}
+		}()
+	}
+
+	_, err := kc.GenerateSession("request-token", "secret")
+	require.Nil(t, err)
+	wg.Wait()
+
+	tok, err := kc.getAccessToken()
+	require.Nil(t, err)
+	require.Equal(t, "new", tok)
+}
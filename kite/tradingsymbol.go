@@ -0,0 +1,247 @@
+package kite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Instrument types of derivative contracts.
+const (
+	InstrumentTypeFUT = "FUT"
+	InstrumentTypeCE  = "CE"
+	InstrumentTypePE  = "PE"
+)
+
+var monthCodes = [...]string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// weeklyMonthCodes holds the single letter month codes of weekly options.
+const weeklyMonthCodes = "123456789OND"
+
+// Contract is a derivative contract as encoded in its tradingsymbol.
+//
+// Monthly contracts are written with the expiry month, as in
+// BANKNIFTY23JULFUT or NIFTY23JUL19500CE. Weekly options are written with
+// the expiry day as YY, M and DD, where M is 1-9 for January to September
+// and O, N and D for October to December, as in NIFTY2372019500CE.
+type Contract struct {
+	// Name is the underlying, as in the name field of the instrument.
+	Name string
+	// Expiry is the expiry day in IST. The tradingsymbol of a monthly
+	// contract only holds the month, so parsing one sets the first of the
+	// month until it is resolved against the instrument master.
+	Expiry time.Time
+	Weekly bool
+	// Strike is 0 for futures.
+	Strike         float64
+	InstrumentType string
+}
+
+// ParseTradingsymbol parses the tradingsymbol of a future or an option.
+func ParseTradingsymbol(tradingsymbol string) (Contract, error) {
+	c, s, ok := parseInstrumentType(tradingsymbol)
+	if !ok {
+		return c, invalidTradingsymbol(tradingsymbol)
+	}
+
+	// The underlying may itself end in digits, as NIFTYNXT50 does, so the
+	// shortest name which leaves a valid expiry and strike wins.
+	for i := 1; i < len(s); i++ {
+		if c, ok := parseExpiryStrike(c, s[i:]); ok {
+			c.Name = s[:i]
+			return c, nil
+		}
+	}
+
+	return c, invalidTradingsymbol(tradingsymbol)
+}
+
+// parseInstrumentType returns the contract with the instrument type of a
+// tradingsymbol set, along with the rest of the tradingsymbol.
+func parseInstrumentType(tradingsymbol string) (Contract, string, bool) {
+	var (
+		c Contract
+		s = strings.ToUpper(tradingsymbol)
+	)
+
+	for _, t := range []string{InstrumentTypeFUT, InstrumentTypeCE, InstrumentTypePE} {
+		if strings.HasSuffix(s, t) {
+			c.InstrumentType = t
+			return c, s[:len(s)-len(t)], true
+		}
+	}
+
+	return c, s, false
+}
+
+// parseExpiryStrike parses the expiry and strike which follow the
+// underlying in a tradingsymbol.
+func parseExpiryStrike(c Contract, s string) (Contract, bool) {
+	if len(s) < 3 || !isDigits(s[:2]) {
+		return c, false
+	}
+
+	year, _ := strconv.Atoi(s[:2])
+	year += 2000
+	s = s[2:]
+
+	// Monthly contract.
+	if len(s) >= 3 {
+		for m, code := range monthCodes {
+			if s[:3] != code {
+				continue
+			}
+
+			c.Expiry = time.Date(year, time.Month(m+1), 1, 0, 0, 0, 0, istLocation())
+			return parseStrike(c, s[3:])
+		}
+	}
+
+	// Weekly option.
+	if c.InstrumentType == InstrumentTypeFUT || len(s) < 4 {
+		return c, false
+	}
+
+	month := strings.IndexByte(weeklyMonthCodes, s[0]) + 1
+	if month == 0 || !isDigits(s[1:3]) {
+		return c, false
+	}
+
+	day, _ := strconv.Atoi(s[1:3])
+
+	c.Expiry = time.Date(year, time.Month(month), day, 0, 0, 0, 0, istLocation())
+	if c.Expiry.Day() != day {
+		return c, false
+	}
+	c.Weekly = true
+
+	return parseStrike(c, s[3:])
+}
+
+func parseStrike(c Contract, s string) (Contract, bool) {
+	if c.InstrumentType == InstrumentTypeFUT {
+		return c, s == ""
+	}
+
+	if s == "" || !isDigit(s[0]) || !isDigits(strings.Replace(s, ".", "", 1)) {
+		return c, false
+	}
+
+	strike, err := strconv.ParseFloat(s, 64)
+	if err != nil || strike <= 0 {
+		return c, false
+	}
+	c.Strike = strike
+
+	return c, true
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func invalidTradingsymbol(s string) error {
+	return NewError(InputError, fmt.Sprintf("Invalid F&O tradingsymbol: %s", s), nil)
+}
+
+// Tradingsymbol formats the tradingsymbol of the contract.
+func (c Contract) Tradingsymbol() string {
+	e := c.Expiry.In(istLocation())
+	year := e.Year() % 100
+
+	var b strings.Builder
+	b.WriteString(c.Name)
+	if c.Weekly && c.InstrumentType != InstrumentTypeFUT {
+		fmt.Fprintf(&b, "%02d%c%02d", year, weeklyMonthCodes[e.Month()-1], e.Day())
+	} else {
+		fmt.Fprintf(&b, "%02d%s", year, monthCodes[e.Month()-1])
+	}
+
+	if c.InstrumentType != InstrumentTypeFUT {
+		b.WriteString(strconv.FormatFloat(c.Strike, 'f', -1, 64))
+	}
+	b.WriteString(c.InstrumentType)
+
+	return b.String()
+}
+
+// matches reports whether the contract parsed from the tradingsymbol of
+// inst agrees with its fields.
+func (c Contract) matches(inst Instrument) bool {
+	if inst.Name != c.Name || inst.InstrumentType != c.InstrumentType || inst.Expiry.IsZero() {
+		return false
+	}
+
+	if c.InstrumentType != InstrumentTypeFUT && inst.StrikePrice != c.Strike {
+		return false
+	}
+
+	if c.Weekly {
+		return sameDay(inst.Expiry.Time, c.Expiry)
+	}
+
+	e, x := inst.Expiry.In(istLocation()), c.Expiry.In(istLocation())
+	return e.Year() == x.Year() && e.Month() == x.Month()
+}
+
+// ParseTradingsymbol parses the tradingsymbol of a future or an option
+// listed on exchange and validates it against the instrument's expiry and
+// strike. The returned contract carries the exact expiry day.
+func (m *InstrumentMaster) ParseTradingsymbol(exchange, tradingsymbol string) (Contract, error) {
+	c, s, ok := parseInstrumentType(tradingsymbol)
+	if !ok {
+		return c, invalidTradingsymbol(tradingsymbol)
+	}
+
+	inst, ok := m.BySymbol(exchange, strings.ToUpper(tradingsymbol))
+	if !ok {
+		return c, NewError(InputError, fmt.Sprintf("Unknown instrument: %s:%s", exchange, tradingsymbol), nil)
+	}
+
+	// The name of the listed instrument tells where the underlying ends.
+	if !strings.HasPrefix(s, inst.Name) {
+		return c, invalidTradingsymbol(tradingsymbol)
+	}
+
+	c, ok = parseExpiryStrike(c, s[len(inst.Name):])
+	if !ok {
+		return c, invalidTradingsymbol(tradingsymbol)
+	}
+	c.Name = inst.Name
+
+	if !c.matches(inst) {
+		return c, NewError(InputError, fmt.Sprintf("Tradingsymbol %s doesn't match the listed contract", tradingsymbol), nil)
+	}
+	c.Expiry = inst.Expiry.Time
+
+	return c, nil
+}
+
+// BuildTradingsymbol formats the tradingsymbol of c and validates that
+// the contract is listed with the same expiry and strike.
+func (m *InstrumentMaster) BuildTradingsymbol(c Contract) (string, error) {
+	s := c.Tradingsymbol()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, i := range m.byName[c.Name] {
+		inst := m.instruments[i]
+		if inst.Tradingsymbol == s && c.matches(inst) {
+			return s, nil
+		}
+	}
+
+	return s, NewError(InputError, fmt.Sprintf("No listed contract for %s", s), nil)
+}
@@ -0,0 +1,91 @@
+package kite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTradingsymbol(t *testing.T) {
+	t.Parallel()
+	ist := istLocation()
+
+	cases := []struct {
+		symbol string
+		want   Contract
+	}{
+		{"NIFTY2372019500CE", Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 20, 0, 0, 0, 0, ist), Weekly: true, Strike: 19500, InstrumentType: "CE"}},
+		{"BANKNIFTY23O0544900PE", Contract{Name: "BANKNIFTY", Expiry: time.Date(2023, 10, 5, 0, 0, 0, 0, ist), Weekly: true, Strike: 44900, InstrumentType: "PE"}},
+		{"FINNIFTY23D1220000CE", Contract{Name: "FINNIFTY", Expiry: time.Date(2023, 12, 12, 0, 0, 0, 0, ist), Weekly: true, Strike: 20000, InstrumentType: "CE"}},
+		{"BANKNIFTY23JULFUT", Contract{Name: "BANKNIFTY", Expiry: time.Date(2023, 7, 1, 0, 0, 0, 0, ist), InstrumentType: "FUT"}},
+		{"NIFTY23JUL19500CE", Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 1, 0, 0, 0, 0, ist), Strike: 19500, InstrumentType: "CE"}},
+		{"USDINR23JUL82.5PE", Contract{Name: "USDINR", Expiry: time.Date(2023, 7, 1, 0, 0, 0, 0, ist), Strike: 82.5, InstrumentType: "PE"}},
+		{"NIFTYNXT5023JULFUT", Contract{Name: "NIFTYNXT50", Expiry: time.Date(2023, 7, 1, 0, 0, 0, 0, ist), InstrumentType: "FUT"}},
+		{"nifty2372019500ce", Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 20, 0, 0, 0, 0, ist), Weekly: true, Strike: 19500, InstrumentType: "CE"}},
+	}
+
+	for _, tc := range cases {
+		c, err := ParseTradingsymbol(tc.symbol)
+		require.Nil(t, err, tc.symbol)
+		require.Equal(t, tc.want, c, tc.symbol)
+	}
+
+	for _, s := range []string{"INFY", "NIFTY23JULCE", "NIFTY2372019500FUT", "NIFTY23JUL19500", "NIFTY23X2019500CE", "NIFTY23JUL1e5CE", "NIFTY23JULXFUT", "NIFTY23732CE"} {
+		_, err := ParseTradingsymbol(s)
+		require.Error(t, err, s)
+		require.Equal(t, InputError, err.(Error).ErrorType)
+	}
+}
+
+func TestContractTradingsymbol(t *testing.T) {
+	t.Parallel()
+	for _, s := range []string{"NIFTY2372019500CE", "BANKNIFTY23O0544900PE", "BANKNIFTY23JULFUT", "NIFTY23JUL19500CE", "USDINR23JUL82.5PE"} {
+		c, err := ParseTradingsymbol(s)
+		require.Nil(t, err)
+		require.Equal(t, s, c.Tradingsymbol())
+	}
+
+	// Monthly contracts only carry the expiry month.
+	c := Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 27, 0, 0, 0, 0, istLocation()), Strike: 19500, InstrumentType: "CE"}
+	require.Equal(t, "NIFTY23JUL19500CE", c.Tradingsymbol())
+	c.Weekly = true
+	require.Equal(t, "NIFTY2372719500CE", c.Tradingsymbol())
+}
+
+func TestInstrumentMasterTradingsymbol(t *testing.T) {
+	t.Parallel()
+	ts, _ := instrumentsServer()
+	defer ts.Close()
+
+	kc := New("test")
+	kc.SetBaseURI(ts.URL)
+	im := NewInstrumentMaster(kc, "")
+	require.Nil(t, im.Load(context.Background()))
+
+	ist := istLocation()
+	c, err := im.ParseTradingsymbol(ExchangeNFO, "NIFTY23JULFUT")
+	require.Nil(t, err)
+	require.True(t, c.Expiry.Equal(time.Date(2023, 7, 27, 0, 0, 0, 0, ist)))
+
+	c, err = im.ParseTradingsymbol(ExchangeNFO, "NIFTY2372019500PE")
+	require.Nil(t, err)
+	require.Equal(t, 19500.0, c.Strike)
+	require.True(t, c.Weekly)
+
+	_, err = im.ParseTradingsymbol(ExchangeNFO, "NIFTY2372019700CE")
+	require.Error(t, err)
+
+	s, err := im.BuildTradingsymbol(Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 20, 0, 0, 0, 0, ist), Weekly: true, Strike: 19600, InstrumentType: "CE"})
+	require.Nil(t, err)
+	require.Equal(t, "NIFTY2372019600CE", s)
+
+	s, err = im.BuildTradingsymbol(Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 27, 0, 0, 0, 0, ist), Strike: 19500, InstrumentType: "CE"})
+	require.Nil(t, err)
+	require.Equal(t, "NIFTY23JUL19500CE", s)
+
+	// The contract isn't listed as a weekly.
+	_, err = im.BuildTradingsymbol(Contract{Name: "NIFTY", Expiry: time.Date(2023, 7, 27, 0, 0, 0, 0, ist), Weekly: true, Strike: 19500, InstrumentType: "CE"})
+	require.Error(t, err)
+}
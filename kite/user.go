@@ -1,8 +1,11 @@
 package kite
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // UserSession represents the response after a successful authentication.
@@ -22,6 +25,110 @@ type UserSessionTokens struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// sessionChecksum returns the SHA-256 checksum of apiKey + token + apiSecret
+// required by the session endpoints.
+func sessionChecksum(apiKey, token, apiSecret string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(apiKey+token+apiSecret)))
+}
+
+// GenerateSession gets a user session details in exchange of request token.
+// Access token is automatically set if the session is retrieved successfully
+// and the client is switched to the token auth scheme. If the token can't be
+// saved to the token store, the session is returned with the error.
+func (c *Client) GenerateSession(requestToken string, apiSecret string) (UserSession, error) {
+	return c.GenerateSessionWithContext(context.Background(), requestToken, apiSecret)
+}
+
+// GenerateSessionWithContext is the context aware variant of GenerateSession.
+func (c *Client) GenerateSessionWithContext(ctx context.Context, requestToken string, apiSecret string) (UserSession, error) {
+	var session UserSession
+
+	params := url.Values{}
+	params.Add("api_key", c.apiKey)
+	params.Add("request_token", requestToken)
+	params.Add("checksum", sessionChecksum(c.apiKey, requestToken, apiSecret))
+
+	err := c.doEnvelope(WithBackend(ctx, BackendConnect), http.MethodPost, URIUserSession, params, nil, &session)
+	if err == nil {
+		c.setAccessToken(session.AccessToken)
+		c.SetAuthScheme(AuthSchemeToken)
+
+		t := NewToken(session.AccessToken)
+		t.RefreshToken = session.RefreshToken
+		err = c.saveToken(t)
+	}
+
+	return session, err
+}
+
+func (c *Client) invalidateToken(ctx context.Context, tokenType string, token string) (bool, error) {
+	var b bool
+
+	params := url.Values{}
+	params.Add("api_key", c.apiKey)
+	params.Add(tokenType, token)
+
+	err := c.doEnvelope(WithBackend(ctx, BackendConnect), http.MethodDelete, URIUserSessionInvalidate, params, nil, nil)
+	if err == nil {
+		b = true
+	}
+
+	return b, err
+}
+
+// InvalidateAccessToken invalidates the current access token.
+func (c *Client) InvalidateAccessToken() (bool, error) {
+	return c.InvalidateAccessTokenWithContext(context.Background())
+}
+
+// InvalidateAccessTokenWithContext is the context aware variant of InvalidateAccessToken.
+func (c *Client) InvalidateAccessTokenWithContext(ctx context.Context) (bool, error) {
+	accessToken, err := c.getAccessToken()
+	if err != nil {
+		return false, err
+	}
+
+	return c.invalidateToken(ctx, "access_token", accessToken)
+}
+
+// RenewAccessToken renews expired access token using valid refresh token.
+// The new access token is set on the client. If it can't be saved to the
+// token store, the session is returned with the error.
+func (c *Client) RenewAccessToken(refreshToken string, apiSecret string) (UserSessionTokens, error) {
+	return c.RenewAccessTokenWithContext(context.Background(), refreshToken, apiSecret)
+}
+
+// RenewAccessTokenWithContext is the context aware variant of RenewAccessToken.
+func (c *Client) RenewAccessTokenWithContext(ctx context.Context, refreshToken string, apiSecret string) (UserSessionTokens, error) {
+	var session UserSessionTokens
+
+	params := url.Values{}
+	params.Add("api_key", c.apiKey)
+	params.Add("refresh_token", refreshToken)
+	params.Add("checksum", sessionChecksum(c.apiKey, refreshToken, apiSecret))
+
+	err := c.doEnvelope(WithBackend(ctx, BackendConnect), http.MethodPost, URIUserSessionRenew, params, nil, &session)
+	if err == nil {
+		c.setAccessToken(session.AccessToken)
+
+		t := NewToken(session.AccessToken)
+		t.RefreshToken = session.RefreshToken
+		err = c.saveToken(t)
+	}
+
+	return session, err
+}
+
+// InvalidateRefreshToken invalidates the given refresh token.
+func (c *Client) InvalidateRefreshToken(refreshToken string) (bool, error) {
+	return c.InvalidateRefreshTokenWithContext(context.Background(), refreshToken)
+}
+
+// InvalidateRefreshTokenWithContext is the context aware variant of InvalidateRefreshToken.
+func (c *Client) InvalidateRefreshTokenWithContext(ctx context.Context, refreshToken string) (bool, error) {
+	return c.invalidateToken(ctx, "refresh_token", refreshToken)
+}
+
 // Bank represents the details of a single bank account entry on a user's file.
 type Bank struct {
 	Name    string `json:"name"`
@@ -92,28 +199,36 @@ type AllMargins struct {
 
 // GetUserProfile gets user profile.
 func (c *Client) GetUserProfile() (UserProfile, error) {
+	return c.GetUserProfileWithContext(context.Background())
+}
+
+// GetUserProfileWithContext is the context aware variant of GetUserProfile.
+func (c *Client) GetUserProfileWithContext(ctx context.Context) (UserProfile, error) {
 	var userProfile UserProfile
-	err := c.doEnvelope(http.MethodGet, URIUserProfile, nil, nil, &userProfile)
+	err := c.doEnvelope(ctx, http.MethodGet, URIUserProfile, nil, nil, &userProfile)
 	return userProfile, err
 }
 
 // GetUserMargins gets all user margins.
 func (c *Client) GetUserMargins() (AllMargins, error) {
-	var allUserMargins AllMargins
-	err := c.doEnvelope(http.MethodGet, URIUserMargins, nil, nil, &allUserMargins)
-	return allUserMargins, err
+	return c.GetUserMarginsWithContext(context.Background())
 }
 
-// GetUserMarginsOMS gets all user margins.
-func (c *Client) GetUserMarginsOMS() (AllMargins, error) {
+// GetUserMarginsWithContext is the context aware variant of GetUserMargins.
+func (c *Client) GetUserMarginsWithContext(ctx context.Context) (AllMargins, error) {
 	var allUserMargins AllMargins
-	err := c.doEnvelope(http.MethodGet, URIUserMarginsOMS, nil, nil, &allUserMargins)
+	err := c.doEnvelope(ctx, http.MethodGet, URIUserMargins, nil, nil, &allUserMargins)
 	return allUserMargins, err
 }
 
 // GetUserSegmentMargins gets segmentwise user margins.
 func (c *Client) GetUserSegmentMargins(segment string) (Margins, error) {
+	return c.GetUserSegmentMarginsWithContext(context.Background(), segment)
+}
+
+// GetUserSegmentMarginsWithContext is the context aware variant of GetUserSegmentMargins.
+func (c *Client) GetUserSegmentMarginsWithContext(ctx context.Context, segment string) (Margins, error) {
 	var margins Margins
-	err := c.doEnvelope(http.MethodGet, fmt.Sprintf(URIUserMarginsSegment, segment), nil, nil, &margins)
+	err := c.doEnvelope(ctx, http.MethodGet, fmt.Sprintf(URIUserMarginsSegment, segment), nil, nil, &margins)
 	return margins, err
 }
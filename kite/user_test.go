@@ -1,7 +1,13 @@
-package kite
+package kite_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetUserProfile(t *testing.T) {
@@ -26,7 +32,7 @@ func TestGetUserMargins(t *testing.T) {
 
 func TestGetUserSegmentMargins(t *testing.T) {
 	t.Parallel()
-	margins, err := getKite().GetUserSegmentMargins("test")
+	margins, err := getKite().GetUserSegmentMargins(kite.MarginsEquity)
 	if err != nil {
 		t.Errorf("Error while reading user margins. Error: %v", err)
 	}
@@ -35,3 +41,76 @@ func TestGetUserSegmentMargins(t *testing.T) {
 		t.Errorf("Incorrect segment margin values.")
 	}
 }
+
+func TestGenerateSession(t *testing.T) {
+	t.Parallel()
+	var (
+		form     url.Values
+		authSeen string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == kite.URIUserSession:
+			form = r.PostForm
+			w.Write([]byte(`{"status":"success","data":{"user_id":"AB1234","access_token":"at","refresh_token":"rt"}}`))
+		case r.Method == http.MethodGet:
+			authSeen = r.Header.Get("Authorization")
+			w.Write([]byte(`{"status":"success","data":{"user_id":"AB1234"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	kc := kite.New("")
+	kc.SetBaseURI(ts.URL)
+	kc.SetAPIKey("key")
+
+	session, err := kc.GenerateSession("req", "secret")
+	require.Nil(t, err)
+	require.Equal(t, "at", session.AccessToken)
+	require.Equal(t, "AB1234", session.UserID)
+	require.Equal(t, "key", form.Get("api_key"))
+	require.Equal(t, "req", form.Get("request_token"))
+	// sha256("keyreqsecret")
+	require.Equal(t, "763338dd74460b90ae4a5da9ea4258b619794676b70ead4db924803e3eba7a3e", form.Get("checksum"))
+
+	_, err = kc.GetUserProfile()
+	require.Nil(t, err)
+	require.Equal(t, "token key:at", authSeen)
+}
+
+func TestRenewAndInvalidateToken(t *testing.T) {
+	t.Parallel()
+	var deleted url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Write([]byte(`{"status":"success","data":{"access_token":"new","refresh_token":"rt"}}`))
+		case http.MethodDelete:
+			deleted = r.URL.Query()
+			w.Write([]byte(`{"status":"success","data":true}`))
+		}
+	}))
+	defer ts.Close()
+
+	kc := kite.New("old")
+	kc.SetBaseURI(ts.URL)
+	kc.SetAPIKey("key")
+
+	tokens, err := kc.RenewAccessToken("rt", "secret")
+	require.Nil(t, err)
+	require.Equal(t, "new", tokens.AccessToken)
+
+	ok, err := kc.InvalidateAccessToken()
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, "new", deleted.Get("access_token"))
+	require.Equal(t, "key", deleted.Get("api_key"))
+}
+
+func TestGetLoginURL(t *testing.T) {
+	t.Parallel()
+	kc := kite.New("")
+	kc.SetAPIKey("key")
+	require.Equal(t, "https://kite.zerodha.com/connect/login?api_key=key&v=3", kc.GetLoginURL())
+}
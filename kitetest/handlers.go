@@ -0,0 +1,1112 @@
+package kitetest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// Layouts of the `from` and `to` params of the historical data endpoint and
+// of the candle timestamps in its response.
+const (
+	historicalParamLayout = "2006-01-02 15:04:05"
+	candleDateLayout      = "2006-01-02T15:04:05-0700"
+)
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, p params)
+
+// params holds the path parameters of a matched route.
+type params map[string]string
+
+type route struct {
+	method   string
+	segments []string
+	handler  handlerFunc
+}
+
+func (s *Server) buildRoutes() []route {
+	rs := []struct {
+		method  string
+		pattern string
+		handler handlerFunc
+	}{
+		{http.MethodPost, kite.URIUserSession, s.handleSession},
+		{http.MethodDelete, kite.URIUserSessionInvalidate, s.handleInvalidateSession},
+		{http.MethodPost, kite.URIUserSessionRenew, s.handleSession},
+		{http.MethodGet, kite.URIUserProfile, s.handleProfile},
+		{http.MethodGet, kite.URIUserMargins, s.handleMargins},
+		{http.MethodGet, "/user/margins/{segment}", s.handleSegmentMargins},
+
+		{http.MethodGet, kite.URIGetOrders, s.handleOrders},
+		{http.MethodGet, kite.URIGetTrades, s.handleTrades},
+		{http.MethodGet, "/orders/{order_id}", s.handleOrderHistory},
+		{http.MethodGet, "/orders/{order_id}/trades", s.handleOrderTrades},
+		{http.MethodPost, "/orders/{variety}", s.handlePlaceOrder},
+		{http.MethodPut, "/orders/{variety}/{order_id}", s.handleModifyOrder},
+		{http.MethodDelete, "/orders/{variety}/{order_id}", s.handleCancelOrder},
+		{http.MethodPost, kite.URIPlaceCharges, s.handleCharges},
+
+		{http.MethodGet, kite.URIGetPositions, s.handlePositions},
+		{http.MethodPut, kite.URIConvertPosition, s.handleConvertPosition},
+		{http.MethodGet, kite.URIGetHoldings, s.handleHoldings},
+
+		{http.MethodPost, kite.URIOrderMargins, s.handleOrderMargins},
+		{http.MethodPost, kite.URIBasketMargins, s.handleBasketMargins},
+
+		{http.MethodGet, kite.URIGetQuote, s.handleQuote},
+		{http.MethodGet, kite.URIGetLTP, s.handleLTP},
+		{http.MethodGet, kite.URIGetOHLC, s.handleOHLC},
+		{http.MethodGet, "/instruments/historical/{instrument_token}/{interval}", s.handleHistorical},
+		{http.MethodGet, kite.URIGetInstruments, s.handleInstruments},
+		{http.MethodGet, "/instruments/{exchange}", s.handleInstruments},
+		{http.MethodGet, kite.URIGetMFInstruments, s.handleMFInstruments},
+
+		{http.MethodGet, kite.URIGetGTTs, s.handleGTTs},
+		{http.MethodPost, kite.URIPlaceGTT, s.handlePlaceGTT},
+		{http.MethodGet, "/gtt/triggers/{id}", s.handleGTT},
+		{http.MethodPut, "/gtt/triggers/{id}", s.handleModifyGTT},
+		{http.MethodDelete, "/gtt/triggers/{id}", s.handleDeleteGTT},
+
+		{http.MethodGet, kite.URIGetMFOrders, s.handleMFOrders},
+		{http.MethodPost, kite.URIPlaceMFOrder, s.handlePlaceMFOrder},
+		{http.MethodGet, "/mf/orders/{order_id}", s.handleMFOrder},
+		{http.MethodDelete, "/mf/orders/{order_id}", s.handleCancelMFOrder},
+		{http.MethodGet, kite.URIGetMFSIPs, s.handleMFSIPs},
+		{http.MethodPost, kite.URIPlaceMFSIP, s.handlePlaceMFSIP},
+		{http.MethodGet, "/mf/sips/{sip_id}", s.handleMFSIP},
+		{http.MethodPut, "/mf/sips/{sip_id}", s.handleModifyMFSIP},
+		{http.MethodDelete, "/mf/sips/{sip_id}", s.handleCancelMFSIP},
+		{http.MethodGet, kite.URIGetMFHoldings, s.handleMFHoldings},
+	}
+
+	out := make([]route, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, route{
+			method:   r.method,
+			segments: strings.Split(strings.Trim(r.pattern, "/"), "/"),
+			handler:  r.handler,
+		})
+	}
+
+	return out
+}
+
+// match returns the handler for a request and its path params. found is
+// true if the path matched a route for some other method.
+func (s *Server) match(method, path string) (h handlerFunc, p params, found bool) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, rt := range s.routes {
+		if len(rt.segments) != len(segs) {
+			continue
+		}
+
+		p := params{}
+		ok := true
+		for i, seg := range rt.segments {
+			if strings.HasPrefix(seg, "{") {
+				p[strings.Trim(seg, "{}")] = segs[i]
+				continue
+			}
+
+			if seg != segs[i] {
+				ok = false
+				break
+			}
+		}
+
+		if !ok {
+			continue
+		}
+
+		if rt.method == method {
+			return rt.handler, p, true
+		}
+		found = true
+	}
+
+	return nil, nil, found
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, omsPathPrefix)
+
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+
+	// JSON bodies must not be parsed as a form.
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		r.ParseForm()
+		r.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   path,
+		Header: r.Header.Clone(),
+		Query:  r.URL.Query(),
+		Form:   r.PostForm,
+		Body:   body,
+	})
+	token := s.accessToken
+	s.mu.Unlock()
+
+	if f := s.matchFault(r.Method, path); f != nil {
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if f.Body != "" {
+			w.WriteHeader(statusOr(f.Status, http.StatusOK))
+			w.Write([]byte(f.Body))
+			return
+		}
+
+		if f.Status != 0 {
+			writeError(w, f.Status, f.ErrorType, f.Message)
+			return
+		}
+	}
+
+	if token != "" && !strings.HasPrefix(path, "/session/") && !hasToken(r.Header.Get("Authorization"), token) {
+		writeError(w, http.StatusForbidden, kite.TokenError, "Incorrect `api_key` or `access_token`.")
+		return
+	}
+
+	h, p, found := s.match(r.Method, path)
+	if h == nil {
+		if found {
+			writeError(w, http.StatusMethodNotAllowed, kite.InputError, "Method not allowed.")
+			return
+		}
+
+		writeError(w, http.StatusNotFound, kite.GeneralError, "Route not found.")
+		return
+	}
+
+	h(w, r, p)
+}
+
+// hasToken reports whether the Authorization header carries token in either
+// the `enctoken` or the `token api_key:access_token` scheme.
+func hasToken(auth, token string) bool {
+	return auth == "enctoken "+token || (strings.HasPrefix(auth, "token ") && strings.HasSuffix(auth, ":"+token))
+}
+
+func statusOr(status, def int) int {
+	if status == 0 {
+		return def
+	}
+
+	return status
+}
+
+// zeroTimeJSON is how a zero kite.Time is marshalled, which kite.Time
+// doesn't parse back. The API sends null for times which aren't set.
+var zeroTimeJSON = []byte(`"0001-01-01T00:00:00Z"`)
+
+// nullZeroTimes replaces the zero times in marshalled JSON with null.
+func nullZeroTimes(b []byte) []byte {
+	return bytes.Replace(b, zeroTimeJSON, []byte("null"), -1)
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	b, err := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, kite.GeneralError, err.Error())
+		return
+	}
+	b = nullZeroTimes(b)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, status int, etype, message string) {
+	if etype == "" {
+		etype = kite.GeneralError
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"status":     "error",
+		"error_type": etype,
+		"message":    message,
+		"data":       nil,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func writeInputError(w http.ResponseWriter, format string, args ...interface{}) {
+	writeError(w, http.StatusBadRequest, kite.InputError, fmt.Sprintf(format, args...))
+}
+
+// newID returns a new numeric ID. Callers must hold s.mu.
+func (s *Server) newID() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func formFloat(r *http.Request, key string) float64 {
+	f, _ := strconv.ParseFloat(r.PostForm.Get(key), 64)
+	return f
+}
+
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	profile := s.profile
+	id := s.newID()
+	s.mu.Unlock()
+
+	writeData(w, kite.UserSession{
+		UserProfile: profile,
+		UserSessionTokens: kite.UserSessionTokens{
+			AccessToken:  fmt.Sprintf("access_token_%d", id),
+			RefreshToken: fmt.Sprintf("refresh_token_%d", id),
+		},
+		UserID: profile.UserID,
+		APIKey: r.PostForm.Get("api_key"),
+	})
+}
+
+func (s *Server) handleInvalidateSession(w http.ResponseWriter, r *http.Request, p params) {
+	writeData(w, true)
+}
+
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, s.profile)
+}
+
+func (s *Server) handleMargins(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, s.margins)
+}
+
+func (s *Server) handleSegmentMargins(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch p["segment"] {
+	case kite.MarginsEquity:
+		writeData(w, s.margins.Equity)
+	case kite.MarginsCommodity:
+		writeData(w, s.margins.Commodity)
+	default:
+		writeInputError(w, "Invalid segment `%s`.", p["segment"])
+	}
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, nonNilOrders(s.orders))
+}
+
+func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, nonNilTrades(s.trades))
+}
+
+// findOrder returns the index of an order in the orderbook, or -1. Callers
+// must hold s.mu.
+func (s *Server) findOrder(orderID string) int {
+	for i, o := range s.orders {
+		if o.OrderID == orderID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Server) handleOrderHistory(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findOrder(p["order_id"])
+	if i < 0 {
+		writeInputError(w, "Order `%s` not found.", p["order_id"])
+		return
+	}
+
+	writeData(w, []kite.Order{s.orders[i]})
+}
+
+func (s *Server) handleOrderTrades(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findOrder(p["order_id"]) < 0 {
+		writeInputError(w, "Order `%s` not found.", p["order_id"])
+		return
+	}
+
+	trades := []kite.Trade{}
+	for _, t := range s.trades {
+		if t.OrderID == p["order_id"] {
+			trades = append(trades, t)
+		}
+	}
+
+	writeData(w, trades)
+}
+
+// handlePlaceOrder adds an OPEN order to the orderbook. Market orders for
+// instruments with a quote are filled immediately at the last price.
+func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request, p params) {
+	f := r.PostForm
+	if f.Get("tradingsymbol") == "" || f.Get("transaction_type") == "" || f.Get("quantity") == "" {
+		writeInputError(w, "Missing tradingsymbol, transaction_type or quantity.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := kite.Time{Time: time.Now()}
+	o := kite.Order{
+		OrderID:           strconv.Itoa(100000000 + s.newID()),
+		Status:            "OPEN",
+		OrderTimestamp:    now,
+		ExchangeTimestamp: now,
+		Variety:           p["variety"],
+		Exchange:          f.Get("exchange"),
+		TradingSymbol:     f.Get("tradingsymbol"),
+		OrderType:         f.Get("order_type"),
+		TransactionType:   f.Get("transaction_type"),
+		Validity:          f.Get("validity"),
+		Product:           f.Get("product"),
+		Quantity:          formFloat(r, "quantity"),
+		DisclosedQuantity: formFloat(r, "disclosed_quantity"),
+		Price:             formFloat(r, "price"),
+		TriggerPrice:      formFloat(r, "trigger_price"),
+		PendingQuantity:   formFloat(r, "quantity"),
+		Tag:               f.Get("tag"),
+	}
+	if o.Tag != "" {
+		o.Tags = []string{o.Tag}
+	}
+
+	if q, ok := s.quotes[o.Exchange+":"+o.TradingSymbol]; ok {
+		o.InstrumentToken = uint32(q.InstrumentToken)
+		if o.OrderType == kite.OrderTypeMarket {
+			o.Status = kite.OrderStatusComplete
+			o.AveragePrice = q.LastPrice
+			o.FilledQuantity = o.Quantity
+			o.PendingQuantity = 0
+
+			s.trades = append(s.trades, kite.Trade{
+				TradeID:           strconv.Itoa(s.newID()),
+				OrderID:           o.OrderID,
+				AveragePrice:      q.LastPrice,
+				Quantity:          o.Quantity,
+				Product:           o.Product,
+				FillTimestamp:     now,
+				ExchangeTimestamp: now,
+				TransactionType:   o.TransactionType,
+				TradingSymbol:     o.TradingSymbol,
+				Exchange:          o.Exchange,
+				InstrumentToken:   o.InstrumentToken,
+			})
+		}
+	}
+
+	s.orders = append(s.orders, o)
+	writeData(w, kite.OrderResponse{OrderID: o.OrderID})
+}
+
+func (s *Server) handleModifyOrder(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findOrder(p["order_id"])
+	if i < 0 {
+		writeInputError(w, "Order `%s` not found.", p["order_id"])
+		return
+	}
+
+	o := &s.orders[i]
+	if o.Status == kite.OrderStatusComplete || o.Status == kite.OrderStatusCancelled || o.Status == kite.OrderStatusRejected {
+		writeError(w, http.StatusBadRequest, kite.OrderError, fmt.Sprintf("Order cannot be modified as it is in %s state.", o.Status))
+		return
+	}
+
+	f := r.PostForm
+	if v := f.Get("order_type"); v != "" {
+		o.OrderType = v
+	}
+	if v := f.Get("validity"); v != "" {
+		o.Validity = v
+	}
+	if f.Get("quantity") != "" {
+		o.Quantity = formFloat(r, "quantity")
+		o.PendingQuantity = o.Quantity - o.FilledQuantity
+	}
+	if f.Get("price") != "" {
+		o.Price = formFloat(r, "price")
+	}
+	if f.Get("trigger_price") != "" {
+		o.TriggerPrice = formFloat(r, "trigger_price")
+	}
+	if f.Get("disclosed_quantity") != "" {
+		o.DisclosedQuantity = formFloat(r, "disclosed_quantity")
+	}
+
+	writeData(w, kite.OrderResponse{OrderID: o.OrderID})
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findOrder(p["order_id"])
+	if i < 0 {
+		writeInputError(w, "Order `%s` not found.", p["order_id"])
+		return
+	}
+
+	o := &s.orders[i]
+	if o.Status == kite.OrderStatusComplete || o.Status == kite.OrderStatusRejected {
+		writeError(w, http.StatusBadRequest, kite.OrderError, fmt.Sprintf("Order cannot be cancelled as it is in %s state.", o.Status))
+		return
+	}
+
+	o.Status = kite.OrderStatusCancelled
+	o.CancelledQuantity = o.PendingQuantity
+	o.PendingQuantity = 0
+
+	writeData(w, kite.OrderResponse{OrderID: o.OrderID})
+}
+
+// handleCharges echoes the orders back with zero charges.
+func (s *Server) handleCharges(w http.ResponseWriter, r *http.Request, p params) {
+	var orders []kite.ChargeOrderParams
+	if err := json.NewDecoder(r.Body).Decode(&orders); err != nil {
+		writeInputError(w, "Invalid JSON body: %v", err)
+		return
+	}
+
+	out := make([]kite.ChargeOrderResponse, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, kite.ChargeOrderResponse{
+			TransactionType: o.TransactionType,
+			Tradingsymbol:   o.TradingSymbol,
+			Exchange:        o.Exchange,
+			Variety:         o.Variety,
+			Product:         o.Product,
+			OrderType:       o.OrderType,
+			Quantity:        o.Quantity,
+			Price:           o.AveragePrice,
+		})
+	}
+
+	writeData(w, out)
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pos := s.positions
+	if pos.Net == nil {
+		pos.Net = []kite.Position{}
+	}
+	if pos.Day == nil {
+		pos.Day = []kite.Position{}
+	}
+
+	writeData(w, pos)
+}
+
+func (s *Server) handleConvertPosition(w http.ResponseWriter, r *http.Request, p params) {
+	if r.PostForm.Get("tradingsymbol") == "" || r.PostForm.Get("new_product") == "" {
+		writeInputError(w, "Missing tradingsymbol or new_product.")
+		return
+	}
+
+	writeData(w, true)
+}
+
+func (s *Server) handleHoldings(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.holdings == nil {
+		writeData(w, kite.Holdings{})
+		return
+	}
+	writeData(w, s.holdings)
+}
+
+func (s *Server) handleOrderMargins(w http.ResponseWriter, r *http.Request, p params) {
+	var orders []kite.OrderMarginParam
+	if err := json.NewDecoder(r.Body).Decode(&orders); err != nil {
+		writeInputError(w, "Invalid JSON body: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.orderMargins == nil {
+		writeData(w, []kite.OrderMargins{})
+		return
+	}
+	writeData(w, s.orderMargins)
+}
+
+func (s *Server) handleBasketMargins(w http.ResponseWriter, r *http.Request, p params) {
+	var orders []kite.OrderMarginParam
+	if err := json.NewDecoder(r.Body).Decode(&orders); err != nil {
+		writeInputError(w, "Invalid JSON body: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, s.basketMargins)
+}
+
+// requestedQuotes returns the seeded quotes for the `i` query params.
+// Unknown instruments are left out, as the API does.
+func (s *Server) requestedQuotes(r *http.Request) kite.Quote {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		out     = kite.Quote{}
+		byToken map[int]kite.QuoteData
+	)
+	for _, i := range r.URL.Query()["i"] {
+		if q, ok := s.quotes[i]; ok {
+			out[i] = q
+			continue
+		}
+
+		// Instruments can also be requested by instrument token.
+		if token, err := strconv.Atoi(i); err == nil {
+			if byToken == nil {
+				byToken = s.quotes.IndexByToken()
+			}
+
+			if q, ok := byToken[token]; ok {
+				out[i] = q
+			}
+		}
+	}
+
+	return out
+}
+
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request, p params) {
+	writeData(w, s.requestedQuotes(r))
+}
+
+func (s *Server) handleLTP(w http.ResponseWriter, r *http.Request, p params) {
+	out := kite.QuoteLTP{}
+	for k, q := range s.requestedQuotes(r) {
+		out[k] = kite.LTPData{InstrumentToken: q.InstrumentToken, LastPrice: q.LastPrice}
+	}
+
+	writeData(w, out)
+}
+
+func (s *Server) handleOHLC(w http.ResponseWriter, r *http.Request, p params) {
+	out := kite.QuoteOHLC{}
+	for k, q := range s.requestedQuotes(r) {
+		out[k] = kite.OHLCData{InstrumentToken: q.InstrumentToken, LastPrice: q.LastPrice, OHLC: q.OHLC}
+	}
+
+	writeData(w, out)
+}
+
+// handleHistorical serves the candles set for the instrument and interval
+// which fall within the requested range, both ends inclusive. The range is
+// read in IST, as it is by the API.
+func (s *Server) handleHistorical(w http.ResponseWriter, r *http.Request, p params) {
+	token, err := strconv.Atoi(p["instrument_token"])
+	if err != nil {
+		writeInputError(w, "Invalid instrument token `%s`.", p["instrument_token"])
+		return
+	}
+
+	q := r.URL.Query()
+	from, err := time.ParseInLocation(historicalParamLayout, q.Get("from"), istLocation())
+	if err != nil {
+		writeInputError(w, "Invalid `from` date: %v", err)
+		return
+	}
+
+	to, err := time.ParseInLocation(historicalParamLayout, q.Get("to"), istLocation())
+	if err != nil {
+		writeInputError(w, "Invalid `to` date: %v", err)
+		return
+	}
+
+	if to.Before(from) {
+		writeInputError(w, "Invalid date range.")
+		return
+	}
+
+	withOI := q.Get("oi") == "1"
+
+	s.mu.Lock()
+	candles := s.candles[candleKey{token, p["interval"]}]
+	s.mu.Unlock()
+
+	out := [][]interface{}{}
+	for _, c := range candles {
+		if c.Date.Before(from) || c.Date.After(to) {
+			continue
+		}
+
+		row := []interface{}{c.Date.Format(candleDateLayout), c.Open, c.High, c.Low, c.Close, c.Volume}
+		if withOI {
+			row = append(row, c.OI)
+		}
+		out = append(out, row)
+	}
+
+	writeData(w, map[string]interface{}{"candles": out})
+}
+
+// istLocation returns the Asia/Kolkata location, falling back to a fixed
+// +05:30 zone when the tz database isn't available.
+func istLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+
+	return loc
+}
+
+func formatCSVTime(t kite.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(layout)
+}
+
+func formatCSVFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// handleInstruments serves the instruments dump as CSV, optionally filtered
+// by exchange.
+func (s *Server) handleInstruments(w http.ResponseWriter, r *http.Request, p params) {
+	exchange := strings.ToUpper(p["exchange"])
+
+	s.mu.Lock()
+	instruments := s.instruments
+	s.mu.Unlock()
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"instrument_token", "exchange_token", "tradingsymbol", "name", "last_price", "expiry", "strike", "tick_size", "lot_size", "instrument_type", "segment", "exchange"})
+	for _, i := range instruments {
+		if exchange != "" && i.Exchange != exchange {
+			continue
+		}
+
+		cw.Write([]string{
+			strconv.Itoa(i.InstrumentToken),
+			strconv.Itoa(i.ExchangeToken),
+			i.Tradingsymbol,
+			i.Name,
+			formatCSVFloat(i.LastPrice),
+			formatCSVTime(i.Expiry, "2006-01-02"),
+			formatCSVFloat(i.StrikePrice),
+			formatCSVFloat(i.TickSize),
+			formatCSVFloat(i.LotSize),
+			i.InstrumentType,
+			i.Segment,
+			i.Exchange,
+		})
+	}
+	cw.Flush()
+}
+
+func (s *Server) handleMFInstruments(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	instruments := s.mfInstruments
+	s.mu.Unlock()
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"tradingsymbol", "amc", "name", "purchase_allowed", "redemption_allowed", "minimum_purchase_amount", "purchase_amount_multiplier", "additional_purchase_multiple", "minimum_redemption_quantity", "redemption_quantity_multiplier", "dividend_type", "scheme_type", "plan", "settlement_type", "last_price", "last_price_date"})
+	for _, i := range instruments {
+		cw.Write([]string{
+			i.Tradingsymbol,
+			i.AMC,
+			i.Name,
+			strconv.FormatBool(i.PurchaseAllowed),
+			strconv.FormatBool(i.RedemtpionAllowed),
+			formatCSVFloat(i.MinimumPurchaseAmount),
+			formatCSVFloat(i.PurchaseAmountMultiplier),
+			formatCSVFloat(i.MinimumAdditionalPurchaseAmount),
+			formatCSVFloat(i.MinimumRedemptionQuantity),
+			formatCSVFloat(i.RedemptionQuantityMultiplier),
+			i.DividendType,
+			i.SchemeType,
+			i.Plan,
+			i.SettlementType,
+			formatCSVFloat(i.LastPrice),
+			formatCSVTime(i.LastPriceDate, "2006-01-02"),
+		})
+	}
+	cw.Flush()
+}
+
+func (s *Server) handleGTTs(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.gtts == nil {
+		writeData(w, kite.GTTs{})
+		return
+	}
+	writeData(w, s.gtts)
+}
+
+// findGTT returns the index of a GTT, or -1. Callers must hold s.mu.
+func (s *Server) findGTT(id string) int {
+	for i, g := range s.gtts {
+		if strconv.Itoa(g.ID) == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseGTT reads the type, condition and orders form params of a GTT.
+func parseGTT(r *http.Request) (kite.GTT, error) {
+	var g kite.GTT
+
+	g.Type = kite.GTTType(r.PostForm.Get("type"))
+	if g.Type != kite.GTTTypeSingle && g.Type != kite.GTTTypeOCO {
+		return g, fmt.Errorf("invalid type `%s`", g.Type)
+	}
+
+	if err := json.Unmarshal([]byte(r.PostForm.Get("condition")), &g.Condition); err != nil {
+		return g, fmt.Errorf("invalid condition: %v", err)
+	}
+
+	// The orders are marshalled by the client, zero times included.
+	if err := json.Unmarshal(nullZeroTimes([]byte(r.PostForm.Get("orders"))), &g.Orders); err != nil {
+		return g, fmt.Errorf("invalid orders: %v", err)
+	}
+
+	return g, nil
+}
+
+func (s *Server) handlePlaceGTT(w http.ResponseWriter, r *http.Request, p params) {
+	g, err := parseGTT(r)
+	if err != nil {
+		writeInputError(w, "%v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := kite.Time{Time: time.Now()}
+	g.ID = s.newID()
+	g.UserID = s.profile.UserID
+	g.Status = "active"
+	g.CreatedAt, g.UpdatedAt = now, now
+	g.ExpiresAt = kite.Time{Time: now.AddDate(1, 0, 0)}
+
+	s.gtts = append(s.gtts, g)
+	writeData(w, kite.GTTResponse{TriggerID: g.ID})
+}
+
+func (s *Server) handleGTT(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findGTT(p["id"])
+	if i < 0 {
+		writeInputError(w, "Trigger `%s` not found.", p["id"])
+		return
+	}
+
+	writeData(w, s.gtts[i])
+}
+
+func (s *Server) handleModifyGTT(w http.ResponseWriter, r *http.Request, p params) {
+	g, err := parseGTT(r)
+	if err != nil {
+		writeInputError(w, "%v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findGTT(p["id"])
+	if i < 0 {
+		writeInputError(w, "Trigger `%s` not found.", p["id"])
+		return
+	}
+
+	old := &s.gtts[i]
+	old.Type, old.Condition, old.Orders = g.Type, g.Condition, g.Orders
+	old.UpdatedAt = kite.Time{Time: time.Now()}
+
+	writeData(w, kite.GTTResponse{TriggerID: old.ID})
+}
+
+// handleDeleteGTT marks a trigger deleted. Deleted triggers are still
+// listed, as they are by the API.
+func (s *Server) handleDeleteGTT(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findGTT(p["id"])
+	if i < 0 {
+		writeInputError(w, "Trigger `%s` not found.", p["id"])
+		return
+	}
+
+	s.gtts[i].Status = "deleted"
+	writeData(w, kite.GTTResponse{TriggerID: s.gtts[i].ID})
+}
+
+func (s *Server) handleMFOrders(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mfOrders == nil {
+		writeData(w, kite.MFOrders{})
+		return
+	}
+	writeData(w, s.mfOrders)
+}
+
+// findMFOrder returns the index of a MF order, or -1. Callers must hold s.mu.
+func (s *Server) findMFOrder(orderID string) int {
+	for i, o := range s.mfOrders {
+		if o.OrderID == orderID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Server) handleMFOrder(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findMFOrder(p["order_id"])
+	if i < 0 {
+		writeInputError(w, "Order `%s` not found.", p["order_id"])
+		return
+	}
+
+	writeData(w, s.mfOrders[i])
+}
+
+func (s *Server) handlePlaceMFOrder(w http.ResponseWriter, r *http.Request, p params) {
+	f := r.PostForm
+	if f.Get("tradingsymbol") == "" || f.Get("transaction_type") == "" {
+		writeInputError(w, "Missing tradingsymbol or transaction_type.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := kite.MFOrder{
+		OrderID:         fmt.Sprintf("mf-%d", s.newID()),
+		Tradingsymbol:   f.Get("tradingsymbol"),
+		Status:          "OPEN",
+		OrderTimestamp:  kite.Time{Time: time.Now()},
+		TransactionType: f.Get("transaction_type"),
+		Variety:         "regular",
+		Quantity:        formFloat(r, "quantity"),
+		Amount:          formFloat(r, "amount"),
+		Tag:             f.Get("tag"),
+	}
+
+	s.mfOrders = append(s.mfOrders, o)
+	writeData(w, kite.MFOrderResponse{OrderID: o.OrderID})
+}
+
+func (s *Server) handleCancelMFOrder(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findMFOrder(p["order_id"])
+	if i < 0 {
+		writeInputError(w, "Order `%s` not found.", p["order_id"])
+		return
+	}
+
+	s.mfOrders[i].Status = kite.OrderStatusCancelled
+	writeData(w, kite.MFOrderResponse{OrderID: s.mfOrders[i].OrderID})
+}
+
+func (s *Server) handleMFSIPs(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mfSIPs == nil {
+		writeData(w, kite.MFSIPs{})
+		return
+	}
+	writeData(w, s.mfSIPs)
+}
+
+// findMFSIP returns the index of a SIP, or -1. Callers must hold s.mu.
+func (s *Server) findMFSIP(sipID string) int {
+	for i, sip := range s.mfSIPs {
+		if sip.ID == sipID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *Server) handleMFSIP(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findMFSIP(p["sip_id"])
+	if i < 0 {
+		writeInputError(w, "SIP `%s` not found.", p["sip_id"])
+		return
+	}
+
+	writeData(w, s.mfSIPs[i])
+}
+
+func (s *Server) handlePlaceMFSIP(w http.ResponseWriter, r *http.Request, p params) {
+	f := r.PostForm
+	if f.Get("tradingsymbol") == "" || f.Get("frequency") == "" {
+		writeInputError(w, "Missing tradingsymbol or frequency.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instalments, _ := strconv.Atoi(f.Get("instalments"))
+	day, _ := strconv.Atoi(f.Get("instalment_day"))
+	sip := kite.MFSIP{
+		ID:                 fmt.Sprintf("sip-%d", s.newID()),
+		Tradingsymbol:      f.Get("tradingsymbol"),
+		TransactionType:    kite.TransactionTypeBuy,
+		Status:             "ACTIVE",
+		Created:            kite.Time{Time: time.Now()},
+		Frequency:          f.Get("frequency"),
+		InstalmentAmount:   formFloat(r, "amount"),
+		Instalments:        instalments,
+		PendingInstalments: instalments,
+		InstalmentDay:      day,
+		TriggerPrice:       formFloat(r, "trigger_price"),
+		Tag:                f.Get("tag"),
+	}
+
+	s.mfSIPs = append(s.mfSIPs, sip)
+	writeData(w, kite.MFSIPResponse{SIPID: sip.ID})
+}
+
+func (s *Server) handleModifyMFSIP(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findMFSIP(p["sip_id"])
+	if i < 0 {
+		writeInputError(w, "SIP `%s` not found.", p["sip_id"])
+		return
+	}
+
+	f := r.PostForm
+	sip := &s.mfSIPs[i]
+	if f.Get("amount") != "" {
+		sip.InstalmentAmount = formFloat(r, "amount")
+	}
+	if v := f.Get("frequency"); v != "" {
+		sip.Frequency = v
+	}
+	if v, err := strconv.Atoi(f.Get("instalment_day")); err == nil {
+		sip.InstalmentDay = v
+	}
+	if v, err := strconv.Atoi(f.Get("instalments")); err == nil {
+		sip.Instalments = v
+	}
+	if v := f.Get("status"); v != "" {
+		sip.Status = v
+	}
+
+	writeData(w, kite.MFSIPResponse{SIPID: sip.ID})
+}
+
+// handleCancelMFSIP marks a SIP cancelled. Cancelled SIPs are still listed.
+func (s *Server) handleCancelMFSIP(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findMFSIP(p["sip_id"])
+	if i < 0 {
+		writeInputError(w, "SIP `%s` not found.", p["sip_id"])
+		return
+	}
+
+	s.mfSIPs[i].Status = "CANCELLED"
+	writeData(w, kite.MFSIPResponse{SIPID: s.mfSIPs[i].ID})
+}
+
+func (s *Server) handleMFHoldings(w http.ResponseWriter, r *http.Request, p params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mfHoldings == nil {
+		writeData(w, kite.MFHoldings{})
+		return
+	}
+	writeData(w, s.mfHoldings)
+}
+
+func nonNilOrders(o kite.Orders) kite.Orders {
+	if o == nil {
+		return kite.Orders{}
+	}
+
+	return o
+}
+
+func nonNilTrades(t kite.Trades) kite.Trades {
+	if t == nil {
+		return kite.Trades{}
+	}
+
+	return t
+}
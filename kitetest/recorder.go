@@ -0,0 +1,364 @@
+package kitetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and fails requests
+	// which were not recorded. No request reaches the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real API and records them.
+	ModeRecord
+)
+
+// redacted replaces scrubbed values in cassettes.
+const redacted = "REDACTED"
+
+// Request query params and form fields which carry credentials.
+var secretParams = []string{"access_token", "refresh_token", "request_token", "enctoken", "checksum", "api_key"}
+
+// JSON fields in request and response bodies which carry credentials or
+// identify the user.
+var secretFields = []string{"access_token", "refresh_token", "enctoken", "public_token", "api_key", "user_id", "placed_by", "account_id", "email", "user_name", "user_shortname"}
+
+// Response headers which are not recorded. Content-Length no longer holds
+// once the body is scrubbed.
+var skipHeaders = []string{"Content-Length", "Date", "Set-Cookie"}
+
+// Cassette is a list of recorded request/response pairs.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the normalized form of a request which is matched on
+// replay.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded response.
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Recorder is a cassette-style http.RoundTripper. In ModeRecord it passes
+// requests through to the real API and records them, with credentials and
+// user IDs scrubbed. In ModeReplay it serves the recorded responses.
+//
+// Requests are matched on method, path, query and body. Query params and
+// form bodies are compared irrespective of their order and JSON bodies
+// irrespective of key order.
+//
+//	rec, err := kitetest.NewRecorder("testdata/orders.json", kitetest.ModeReplay)
+//	kc := kite.New("")
+//	kc.SetHTTPClient(rec.Client())
+type Recorder struct {
+	path string
+	mode Mode
+
+	// Transport is used to make requests in ModeRecord. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+
+	secretsMu sync.RWMutex
+	secrets   []string
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In ModeReplay the
+// cassette is loaded from path. In ModeRecord a new cassette is started,
+// which is written to path by Save.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		path: path,
+		mode: mode,
+	}
+
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("kitetest: invalid cassette %s: %v", path, err)
+		}
+
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Redact scrubs the given values wherever they appear in recorded requests
+// and responses, such as a user ID inside an order tag. Replayed requests
+// are scrubbed the same way, so they still match.
+func (r *Recorder) Redact(values ...string) {
+	r.secretsMu.Lock()
+	defer r.secretsMu.Unlock()
+
+	for _, v := range values {
+		if v != "" {
+			r.secrets = append(r.secrets, v)
+		}
+	}
+}
+
+// Client returns an HTTP client which uses the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded cassette to its path. It is a no-op in
+// ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	rr := r.normalize(req, body)
+
+	if r.mode == ModeRecord {
+		return r.record(req, rr)
+	}
+
+	return r.replay(req, rr)
+}
+
+func (r *Recorder) record(req *http.Request, rr RecordedRequest) (*http.Response, error) {
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+
+	resp, err := t.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	for _, h := range skipHeaders {
+		header.Del(h)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: rr,
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: header,
+			Body:   r.scrubBody(body),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves the first unused matching interaction. Once all matching
+// interactions have been used the last one is served again, so that polling
+// the same endpoint keeps working.
+func (r *Recorder) replay(req *http.Request, rr RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request != rr {
+			continue
+		}
+
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("kitetest: no recorded interaction for %s %s?%s", rr.Method, rr.Path, rr.Query)
+	}
+	r.used[match] = true
+
+	rec := r.cassette.Interactions[match].Response
+	header := rec.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// normalize returns the scrubbed, order independent form of a request.
+func (r *Recorder) normalize(req *http.Request, body []byte) RecordedRequest {
+	rr := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  r.normalizeValues(req.URL.Query()),
+	}
+
+	ct := req.Header.Get("Content-Type")
+	switch {
+	case len(body) == 0:
+	case strings.HasPrefix(ct, "application/x-www-form-urlencoded"):
+		v, err := url.ParseQuery(string(body))
+		if err == nil {
+			rr.Body = r.normalizeValues(v)
+			break
+		}
+		rr.Body = r.redactSecrets(string(body))
+	default:
+		rr.Body = r.scrubBody(body)
+	}
+
+	return rr
+}
+
+// normalizeValues scrubs secret params and encodes v sorted by key.
+func (r *Recorder) normalizeValues(v url.Values) string {
+	out := url.Values{}
+	for k, vals := range v {
+		for _, val := range vals {
+			if contains(secretParams, k) {
+				val = redacted
+			}
+			out.Add(k, r.redactSecrets(val))
+		}
+		sort.Strings(out[k])
+	}
+
+	// Encode sorts by key.
+	return out.Encode()
+}
+
+// scrubBody scrubs a JSON body and re-encodes it with sorted keys. Other
+// bodies, such as the instruments CSV, only have redacted values replaced.
+func (r *Recorder) scrubBody(body []byte) string {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return r.redactSecrets(string(body))
+	}
+
+	b, err := json.Marshal(r.scrubJSON(v))
+	if err != nil {
+		return r.redactSecrets(string(body))
+	}
+
+	return string(b)
+}
+
+func (r *Recorder) scrubJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if s, ok := val.(string); ok && s != "" && contains(secretFields, k) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = r.scrubJSON(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = r.scrubJSON(val)
+		}
+	case string:
+		return r.redactSecrets(t)
+	}
+
+	return v
+}
+
+func (r *Recorder) redactSecrets(s string) string {
+	r.secretsMu.RLock()
+	defer r.secretsMu.RUnlock()
+
+	for _, secret := range r.secrets {
+		s = strings.Replace(s, secret, redacted, -1)
+	}
+
+	return s
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package kitetest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "re-record the cassettes in testdata")
+
+const edgeCasesCassette = "testdata/edge_cases.json"
+
+// seedEdgeCases loads responses whose decoding is easy to break: iceberg
+// order meta and GTT rejection reasons.
+func seedEdgeCases(srv *Server) {
+	srv.SetProfile(kite.UserProfile{UserID: "AB1234", Email: "ab@example.com"})
+	srv.AddOrders(kite.Order{
+		OrderID:   "230714200050319",
+		PlacedBy:  "AB1234",
+		AccountID: "AB1234",
+		Status:    "OPEN",
+		Variety:   kite.VarietyIceberg,
+		Validity:  kite.ValidityTTL,
+		Tag:       "AB1234-iceberg",
+		Meta: map[string]interface{}{
+			"iceberg": map[string]interface{}{"leg": 1, "legs": 5, "leg_quantity": 200, "total_quantity": 1000, "remaining_quantity": 800},
+		},
+	})
+	srv.AddGTTs(kite.GTT{
+		ID:     112127,
+		UserID: "AB1234",
+		Type:   kite.GTTTypeSingle,
+		Status: "rejected",
+		Meta:   kite.GTTMeta{RejectionReason: "Your order could not be placed due to insufficient funds."},
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	srv.SetAccessToken("secret_enctoken")
+	seedEdgeCases(srv)
+
+	dir, err := ioutil.TempDir("", "kitetest")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	rec, err := NewRecorder(path, ModeRecord)
+	require.Nil(t, err)
+	rec.Redact("AB1234")
+
+	kc := srv.NewClient()
+	kc.SetHTTPClient(rec.Client())
+
+	recorded, err := kc.GetOrders()
+	require.Nil(t, err)
+	// The caller sees the real response.
+	require.Equal(t, "AB1234", recorded[0].PlacedBy)
+
+	_, err = kc.GetOrderMargins(kite.GetMarginParams{
+		OrderParams: []kite.OrderMarginParam{{Exchange: kite.ExchangeNSE, Tradingsymbol: "INFY", Quantity: 1}},
+	})
+	require.Nil(t, err)
+	_, err = kc.GetGTT(112127)
+	require.Nil(t, err)
+	require.Nil(t, rec.Save())
+	srv.Close()
+
+	b, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	require.False(t, strings.Contains(string(b), "secret_enctoken"))
+	require.False(t, strings.Contains(string(b), "AB1234"))
+
+	// Replay without a server behind the client.
+	rep, err := NewRecorder(path, ModeReplay)
+	require.Nil(t, err)
+	rep.Redact("AB1234")
+	kc.SetHTTPClient(rep.Client())
+
+	replayed, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, recorded[0].OrderID, replayed[0].OrderID)
+	require.Equal(t, recorded[0].Meta, replayed[0].Meta)
+	require.Equal(t, redacted, replayed[0].PlacedBy)
+	require.Equal(t, redacted+"-iceberg", replayed[0].Tag)
+
+	// JSON bodies match irrespective of key order and formatting.
+	_, err = kc.GetOrderMargins(kite.GetMarginParams{
+		OrderParams: []kite.OrderMarginParam{{Tradingsymbol: "INFY", Exchange: kite.ExchangeNSE, Quantity: 1}},
+	})
+	require.Nil(t, err)
+
+	// Requests which weren't recorded fail.
+	_, err = kc.GetOrderMargins(kite.GetMarginParams{
+		OrderParams: []kite.OrderMarginParam{{Exchange: kite.ExchangeNSE, Tradingsymbol: "INFY", Quantity: 2}},
+	})
+	require.Error(t, err)
+	_, err = kc.GetGTT(1)
+	require.Error(t, err)
+
+	// Interactions are served again once used up.
+	_, err = kc.GetOrders()
+	require.Nil(t, err)
+}
+
+func TestReplayEdgeCases(t *testing.T) {
+	if *update {
+		srv := NewServer()
+		seedEdgeCases(srv)
+
+		rec, err := NewRecorder(edgeCasesCassette, ModeRecord)
+		require.Nil(t, err)
+		rec.Redact("AB1234")
+
+		kc := srv.NewClient()
+		kc.SetHTTPClient(rec.Client())
+		_, err = kc.GetOrders()
+		require.Nil(t, err)
+		_, err = kc.GetGTT(112127)
+		require.Nil(t, err)
+		require.Nil(t, rec.Save())
+		srv.Close()
+	}
+
+	rec, err := NewRecorder(edgeCasesCassette, ModeReplay)
+	require.Nil(t, err)
+
+	kc := kite.New("")
+	kc.SetBaseURI("http://127.0.0.1:1")
+	kc.SetRateLimiter(nil)
+	kc.SetHTTPClient(rec.Client())
+
+	orders, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(orders))
+	require.Equal(t, kite.VarietyIceberg, orders[0].Variety)
+	iceberg := orders[0].Meta["iceberg"].(map[string]interface{})
+	require.Equal(t, 200.0, iceberg["leg_quantity"])
+	require.Equal(t, 1000.0, iceberg["total_quantity"])
+
+	gtt, err := kc.GetGTT(112127)
+	require.Nil(t, err)
+	require.Equal(t, "rejected", gtt.Status)
+	require.Equal(t, "Your order could not be placed due to insufficient funds.", gtt.Meta.RejectionReason)
+}
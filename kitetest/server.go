@@ -0,0 +1,328 @@
+// Package kitetest provides an in-process stand-in for the Kite Connect API
+// for use in tests.
+//
+// The server keeps orders, trades, positions, holdings, quotes, candles,
+// instruments, GTTs, margins and mutual fund data in memory. Orders and GTTs
+// placed through the API are added to that state, so tests can place an order
+// and read it back from the orderbook. Failures can be scripted with Inject.
+//
+//	srv := kitetest.NewServer()
+//	defer srv.Close()
+//
+//	srv.SetQuotes(kite.Quote{"NSE:INFY": {InstrumentToken: 408065, LastPrice: 1450}})
+//	kc := srv.NewClient()
+//	quotes, err := kc.GetQuote("NSE:INFY")
+package kitetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+)
+
+// omsPathPrefix is stripped from incoming paths so that the server also
+// stands in for the web OMS backend.
+const omsPathPrefix = "/oms"
+
+// Fault is a scripted failure returned for matching requests.
+type Fault struct {
+	// Method and Path select the requests the fault applies to. An empty
+	// Method matches all methods and Path matches as a prefix of the request
+	// path (without the /oms prefix).
+	Method string
+	Path   string
+
+	// Times is the number of matching requests that fail. Zero fails every
+	// matching request until ClearFaults is called.
+	Times int
+
+	// Delay is slept before responding.
+	Delay time.Duration
+
+	// Status, ErrorType and Message make up the error envelope sent back.
+	// If Body is set it is sent as is instead of an envelope. A fault with
+	// neither Status nor Body only delays the request.
+	Status    int
+	ErrorType string
+	Message   string
+	Body      string
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Query  url.Values
+	Form   url.Values
+	Body   []byte
+}
+
+// Server is an in-memory Kite Connect API server.
+type Server struct {
+	// URL is the base URL of the server, of the form http://ipaddr:port
+	// with no trailing slash.
+	URL string
+
+	ts     *httptest.Server
+	routes []route
+
+	mu          sync.Mutex
+	accessToken string
+	nextID      int
+	faults      []*Fault
+	requests    []Request
+
+	profile       kite.UserProfile
+	margins       kite.AllMargins
+	orders        kite.Orders
+	trades        kite.Trades
+	positions     kite.Positions
+	holdings      kite.Holdings
+	quotes        kite.Quote
+	candles       map[candleKey][]kite.HistoricalData
+	instruments   kite.Instruments
+	mfInstruments kite.MFInstruments
+	gtts          kite.GTTs
+	orderMargins  []kite.OrderMargins
+	basketMargins kite.BasketMargins
+	mfOrders      kite.MFOrders
+	mfSIPs        kite.MFSIPs
+	mfHoldings    kite.MFHoldings
+}
+
+type candleKey struct {
+	token    int
+	interval string
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		nextID:    1,
+		candles:   map[candleKey][]kite.HistoricalData{},
+		quotes:    kite.Quote{},
+		positions: kite.Positions{Net: []kite.Position{}, Day: []kite.Position{}},
+	}
+
+	s.routes = s.buildRoutes()
+	s.ts = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.ts.URL
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.ts.Close()
+}
+
+// NewClient returns a Kite client pointed at the server for both the Connect
+// and OMS backends, with rate limiting disabled.
+func (s *Server) NewClient() *kite.Client {
+	kc := kite.New(s.token())
+	kc.SetBaseURI(s.URL)
+	kc.SetOMSBaseURI(s.URL)
+	kc.SetRateLimiter(nil)
+	return kc
+}
+
+// SetAccessToken makes the server reject requests which don't carry token
+// with a TokenException. An empty token disables the check.
+func (s *Server) SetAccessToken(token string) {
+	s.mu.Lock()
+	s.accessToken = token
+	s.mu.Unlock()
+}
+
+func (s *Server) token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken
+}
+
+// Inject scripts a failure for matching requests. Faults are matched in the
+// order they were injected.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	s.faults = append(s.faults, &f)
+	s.mu.Unlock()
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	s.faults = nil
+	s.mu.Unlock()
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// SetProfile sets the user profile.
+func (s *Server) SetProfile(p kite.UserProfile) {
+	s.mu.Lock()
+	s.profile = p
+	s.mu.Unlock()
+}
+
+// SetMargins sets the user margins.
+func (s *Server) SetMargins(m kite.AllMargins) {
+	s.mu.Lock()
+	s.margins = m
+	s.mu.Unlock()
+}
+
+// AddOrders adds orders to the orderbook.
+func (s *Server) AddOrders(orders ...kite.Order) {
+	s.mu.Lock()
+	s.orders = append(s.orders, orders...)
+	s.mu.Unlock()
+}
+
+// AddTrades adds trades to the tradebook.
+func (s *Server) AddTrades(trades ...kite.Trade) {
+	s.mu.Lock()
+	s.trades = append(s.trades, trades...)
+	s.mu.Unlock()
+}
+
+// SetPositions sets the net and day positions.
+func (s *Server) SetPositions(p kite.Positions) {
+	s.mu.Lock()
+	s.positions = p
+	s.mu.Unlock()
+}
+
+// SetHoldings sets the holdings.
+func (s *Server) SetHoldings(h kite.Holdings) {
+	s.mu.Lock()
+	s.holdings = h
+	s.mu.Unlock()
+}
+
+// SetQuotes adds or replaces quotes keyed by `exchange:tradingsymbol`. The
+// LTP and OHLC endpoints are served from the same quotes.
+func (s *Server) SetQuotes(q kite.Quote) {
+	s.mu.Lock()
+	for k, v := range q {
+		s.quotes[k] = v
+	}
+	s.mu.Unlock()
+}
+
+// SetCandles sets the historical candles of an instrument for an interval.
+// Candles are served filtered by the requested date range.
+func (s *Server) SetCandles(instrumentToken int, interval string, candles []kite.HistoricalData) {
+	s.mu.Lock()
+	s.candles[candleKey{instrumentToken, interval}] = candles
+	s.mu.Unlock()
+}
+
+// SetInstruments sets the instruments served as CSV.
+func (s *Server) SetInstruments(i kite.Instruments) {
+	s.mu.Lock()
+	s.instruments = i
+	s.mu.Unlock()
+}
+
+// SetMFInstruments sets the mutual fund instruments served as CSV.
+func (s *Server) SetMFInstruments(i kite.MFInstruments) {
+	s.mu.Lock()
+	s.mfInstruments = i
+	s.mu.Unlock()
+}
+
+// AddGTTs adds GTT triggers.
+func (s *Server) AddGTTs(gtts ...kite.GTT) {
+	s.mu.Lock()
+	s.gtts = append(s.gtts, gtts...)
+	s.mu.Unlock()
+}
+
+// SetOrderMargins sets the response of the order margins endpoint.
+func (s *Server) SetOrderMargins(m []kite.OrderMargins) {
+	s.mu.Lock()
+	s.orderMargins = m
+	s.mu.Unlock()
+}
+
+// SetBasketMargins sets the response of the basket margins endpoint.
+func (s *Server) SetBasketMargins(m kite.BasketMargins) {
+	s.mu.Lock()
+	s.basketMargins = m
+	s.mu.Unlock()
+}
+
+// AddMFOrders adds mutual fund orders.
+func (s *Server) AddMFOrders(orders ...kite.MFOrder) {
+	s.mu.Lock()
+	s.mfOrders = append(s.mfOrders, orders...)
+	s.mu.Unlock()
+}
+
+// AddMFSIPs adds mutual fund SIPs.
+func (s *Server) AddMFSIPs(sips ...kite.MFSIP) {
+	s.mu.Lock()
+	s.mfSIPs = append(s.mfSIPs, sips...)
+	s.mu.Unlock()
+}
+
+// SetMFHoldings sets the mutual fund holdings.
+func (s *Server) SetMFHoldings(h kite.MFHoldings) {
+	s.mu.Lock()
+	s.mfHoldings = h
+	s.mu.Unlock()
+}
+
+// Orders returns the current orderbook.
+func (s *Server) Orders() kite.Orders {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(kite.Orders(nil), s.orders...)
+}
+
+// GTTs returns the current GTT triggers.
+func (s *Server) GTTs() kite.GTTs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(kite.GTTs(nil), s.gtts...)
+}
+
+// matchFault returns the first fault matching the request and consumes it.
+func (s *Server) matchFault(method, path string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+
+		out := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+
+		return &out
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package kitetest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/santoshanand/at-kite/kite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderLifecycle(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.SetQuotes(kite.Quote{"NSE:INFY": {InstrumentToken: 408065, LastPrice: 1450}})
+	kc := srv.NewClient()
+
+	limit, err := kc.PlaceOrder(kite.VarietyRegular, kite.OrderParams{
+		Exchange:        kite.ExchangeNSE,
+		Tradingsymbol:   "INFY",
+		TransactionType: kite.TransactionTypeBuy,
+		OrderType:       kite.OrderTypeLimit,
+		Product:         kite.ProductCNC,
+		Quantity:        10,
+		Price:           1400,
+	})
+	require.Nil(t, err)
+
+	market, err := kc.PlaceOrder(kite.VarietyRegular, kite.OrderParams{
+		Exchange:        kite.ExchangeNSE,
+		Tradingsymbol:   "INFY",
+		TransactionType: kite.TransactionTypeSell,
+		OrderType:       kite.OrderTypeMarket,
+		Product:         kite.ProductCNC,
+		Quantity:        5,
+	})
+	require.Nil(t, err)
+
+	_, err = kc.ModifyOrder(kite.VarietyRegular, limit.OrderID, kite.OrderParams{Price: 1410})
+	require.Nil(t, err)
+
+	orders, err := kc.GetOrders()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(orders))
+	require.Equal(t, "OPEN", orders[0].Status)
+	require.Equal(t, 1410.0, orders[0].Price)
+	require.Equal(t, uint32(408065), orders[0].InstrumentToken)
+	require.Equal(t, kite.OrderStatusComplete, orders[1].Status)
+	require.Equal(t, 1450.0, orders[1].AveragePrice)
+
+	trades, err := kc.GetOrderTrades(market.OrderID)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(trades))
+	require.Equal(t, 5.0, trades[0].Quantity)
+
+	_, err = kc.CancelOrder(kite.VarietyRegular, limit.OrderID, nil)
+	require.Nil(t, err)
+	history, err := kc.GetOrderHistory(limit.OrderID)
+	require.Nil(t, err)
+	require.Equal(t, kite.OrderStatusCancelled, history[0].Status)
+	require.Equal(t, 10.0, history[0].CancelledQuantity)
+
+	// Filled orders can't be cancelled.
+	_, err = kc.CancelOrder(kite.VarietyRegular, market.OrderID, nil)
+	require.Error(t, err)
+	require.Equal(t, kite.OrderError, err.(kite.Error).ErrorType)
+
+	_, err = kc.GetOrderHistory("missing")
+	require.Error(t, err)
+	require.Equal(t, kite.InputError, err.(kite.Error).ErrorType)
+}
+
+func TestOMSPrefix(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.AddOrders(kite.Order{OrderID: "1"})
+	kc := srv.NewClient()
+
+	orders, err := kc.GetOrdersWithContext(kite.WithBackend(context.Background(), kite.BackendOMS))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(orders))
+
+	reqs := srv.Requests()
+	require.Equal(t, 1, len(reqs))
+	require.Equal(t, kite.URIGetOrders, reqs[0].Path)
+}
+
+func TestInject(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	kc := srv.NewClient()
+	kc.SetRetryPolicy(kite.RetryPolicy{MaxAttempts: 1})
+
+	srv.Inject(Fault{
+		Method:    http.MethodGet,
+		Path:      kite.URIGetHoldings,
+		Times:     1,
+		Status:    http.StatusTooManyRequests,
+		ErrorType: kite.NetworkError,
+		Message:   "Too many requests",
+	})
+
+	_, err := kc.GetHoldings()
+	require.Error(t, err)
+	require.Equal(t, http.StatusTooManyRequests, err.(kite.Error).Code)
+	require.Equal(t, "Too many requests", err.(kite.Error).Message)
+
+	// The fault was used up.
+	_, err = kc.GetHoldings()
+	require.Nil(t, err)
+
+	// Other endpoints are not affected.
+	srv.Inject(Fault{Path: kite.URIGetPositions, Body: "not json"})
+	_, err = kc.GetHoldings()
+	require.Nil(t, err)
+	_, err = kc.GetPositions()
+	require.Error(t, err)
+	_, err = kc.GetPositions()
+	require.Error(t, err)
+
+	srv.ClearFaults()
+	_, err = kc.GetPositions()
+	require.Nil(t, err)
+
+	srv.Inject(Fault{Path: kite.URIGetTrades, Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = kc.GetTradesWithContext(ctx)
+	require.Error(t, err)
+	require.Equal(t, kite.ContextError, err.(kite.Error).ErrorType)
+}
+
+func TestAccessToken(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.SetAccessToken("secret")
+	kc := srv.NewClient()
+
+	_, err := kc.GetUserProfile()
+	require.Nil(t, err)
+
+	kc.SetAccessToken("wrong")
+	_, err = kc.GetUserProfile()
+	require.Error(t, err)
+	require.Equal(t, kite.TokenError, err.(kite.Error).ErrorType)
+
+	kc.SetAPIKey("key")
+	_, err = kc.GenerateSession("request_token", "api_secret")
+	require.Nil(t, err)
+}
+
+func TestHistoricalRange(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	ist := istLocation()
+	var candles []kite.HistoricalData
+	for i := 0; i < 10; i++ {
+		candles = append(candles, kite.HistoricalData{
+			Date:  kite.Time{Time: time.Date(2023, 7, 14, 9, 15+i, 0, 0, ist)},
+			Close: float64(i),
+			OI:    i + 1,
+		})
+	}
+	srv.SetCandles(256265, "minute", candles)
+	kc := srv.NewClient()
+
+	from := time.Date(2023, 7, 14, 9, 17, 0, 0, ist)
+	data, err := kc.GetHistoricalData(256265, "minute", from, from.Add(3*time.Minute), false, true)
+	require.Nil(t, err)
+	require.Equal(t, 4, len(data))
+	require.True(t, from.Equal(data[0].Date.Time))
+	require.Equal(t, 2.0, data[0].Close)
+	require.Equal(t, 3, data[0].OI)
+
+	data, err = kc.GetHistoricalData(256265, "day", from, from.Add(time.Hour), false, false)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(data))
+}
+
+func TestInstrumentsCSV(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	expiry := kite.Time{Time: time.Date(2023, 7, 27, 0, 0, 0, 0, istLocation())}
+	srv.SetInstruments(kite.Instruments{
+		{InstrumentToken: 408065, Tradingsymbol: "INFY", Exchange: kite.ExchangeNSE, TickSize: 0.05, LotSize: 1},
+		{InstrumentToken: 13238786, Tradingsymbol: "NIFTY23JULFUT", Exchange: kite.ExchangeNFO, Expiry: expiry, LotSize: 50},
+	})
+	kc := srv.NewClient()
+
+	all, err := kc.GetInstruments()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(all))
+	require.Equal(t, 0.05, all[0].TickSize)
+	require.True(t, all[0].Expiry.IsZero())
+	require.True(t, expiry.Equal(all[1].Expiry.Time))
+
+	nfo, err := kc.GetInstrumentsByExchange("nfo")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(nfo))
+	require.Equal(t, "NIFTY23JULFUT", nfo[0].Tradingsymbol)
+}
+
+func TestGTTLifecycle(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	kc := srv.NewClient()
+	params := kite.GTTParams{
+		Tradingsymbol:   "INFY",
+		Exchange:        kite.ExchangeNSE,
+		LastPrice:       1450,
+		TransactionType: kite.TransactionTypeSell,
+		Trigger: &kite.GTTOneCancelsOtherTrigger{
+			Upper: kite.TriggerParams{TriggerValue: 1500, LimitPrice: 1500, Quantity: 1},
+			Lower: kite.TriggerParams{TriggerValue: 1400, LimitPrice: 1400, Quantity: 1},
+		},
+	}
+
+	resp, err := kc.PlaceGTT(params)
+	require.Nil(t, err)
+
+	g, err := kc.GetGTT(resp.TriggerID)
+	require.Nil(t, err)
+	require.Equal(t, kite.GTTTypeOCO, g.Type)
+	require.Equal(t, []float64{1400, 1500}, g.Condition.TriggerValues)
+	require.Equal(t, 2, len(g.Orders))
+
+	_, err = kc.DeleteGTT(resp.TriggerID)
+	require.Nil(t, err)
+	require.Equal(t, "deleted", srv.GTTs()[0].Status)
+}